@@ -0,0 +1,140 @@
+// Package auth authenticates the callers of gRPC services, by their client
+// certificate and bearer token, and authorizes their calls with a policy.
+//
+// The authenticators and the policy check calls with an Authorize method,
+// which can be given to guide.WithAuth, alone or combined with Chain:
+//
+//	authorize := auth.Chain(auth.AuthenticatePeer, tokens.Authorize, policy.Authorize)
+//	s := guide.New(guide.WithAuth(authorize))
+//
+// The handlers then get the caller with IdentityFromContext.
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity is the authenticated caller of an RPC, taken from the client
+// certificate it presented and the bearer token it sent.
+type Identity struct {
+	CommonName string
+	// SANs holds the DNS names, email addresses, IP addresses and URIs of the
+	// certificate.
+	SANs []string
+	// Subject is the subject of the bearer token.
+	Subject string
+}
+
+// Principals returns the names the identity is known by in policies.
+func (id *Identity) Principals() []string {
+	var principals []string
+	if id.CommonName != "" {
+		principals = append(principals, "cn:"+id.CommonName)
+	}
+	for _, san := range id.SANs {
+		principals = append(principals, "san:"+san)
+	}
+	if id.Subject != "" {
+		principals = append(principals, "token:"+id.Subject)
+	}
+	return principals
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity of the caller, if it was
+// authenticated.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// PeerIdentity returns the identity of the verified client certificate of
+// the peer of the RPC, if any.
+func PeerIdentity(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	cert := info.State.VerifiedChains[0][0]
+	id := &Identity{CommonName: cert.Subject.CommonName}
+	id.SANs = append(id.SANs, cert.DNSNames...)
+	id.SANs = append(id.SANs, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		id.SANs = append(id.SANs, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		id.SANs = append(id.SANs, uri.String())
+	}
+	return id, true
+}
+
+// AuthenticatePeer returns ctx carrying the identity of the client
+// certificate of the peer, if it has one. It never fails, so calls without
+// a certificate are left to the authorizers chained after it.
+func AuthenticatePeer(ctx context.Context, _ string) (context.Context, error) {
+	if id, ok := PeerIdentity(ctx); ok {
+		return withIdentity(ctx, id), nil
+	}
+	return ctx, nil
+}
+
+// Chain returns an authorizer running every authorizer in order, each with
+// the context returned by the previous one, until one of them fails.
+func Chain(authorizers ...func(ctx context.Context, fullMethod string) (context.Context, error)) func(ctx context.Context, fullMethod string) (context.Context, error) {
+	return func(ctx context.Context, fullMethod string) (context.Context, error) {
+		for _, authorize := range authorizers {
+			var err error
+			if ctx, err = authorize(ctx, fullMethod); err != nil {
+				return nil, err
+			}
+		}
+		return ctx, nil
+	}
+}
+
+// withIdentity returns ctx carrying id, which is also kept in the holder of
+// the call, if any.
+func withIdentity(ctx context.Context, id *Identity) context.Context {
+	if h, ok := ctx.Value(identityHolderKey{}).(*IdentityHolder); ok {
+		h.id = id
+	}
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityHolder keeps the last identity authenticated in the context of a
+// call, so the interceptors running before the authorizers, like logging
+// ones, can read it once the call is finished.
+type IdentityHolder struct {
+	id *Identity
+}
+
+type identityHolderKey struct{}
+
+// WithIdentityHolder returns ctx carrying a new holder of the identity of
+// the caller.
+func WithIdentityHolder(ctx context.Context) (context.Context, *IdentityHolder) {
+	h := &IdentityHolder{}
+	return context.WithValue(ctx, identityHolderKey{}, h), h
+}
+
+// Identity returns the last identity authenticated, if any.
+func (h *IdentityHolder) Identity() (*Identity, bool) {
+	return h.id, h.id != nil
+}
+
+// isHealthCheck reports whether the method belongs to the health service,
+// whose calls are always allowed, as load balancers probing the server have
+// no way to get credentials.
+func isHealthCheck(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/")
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var ran []string
+	add := func(name string, err error) func(context.Context, string) (context.Context, error) {
+		return func(ctx context.Context, _ string) (context.Context, error) {
+			ran = append(ran, name)
+			if err != nil {
+				return nil, err
+			}
+			id := &Identity{}
+			if prev, ok := IdentityFromContext(ctx); ok {
+				*id = *prev
+			}
+			id.SANs = append(id.SANs, name)
+			return withIdentity(ctx, id), nil
+		}
+	}
+	denied := errors.New("denied")
+
+	ctx, holder := WithIdentityHolder(context.Background())
+	ctx, err := Chain(add("a", nil), add("b", nil))(ctx, "/s/M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, ok := IdentityFromContext(ctx)
+	if !ok || strings.Join(id.SANs, ",") != "a,b" {
+		t.Errorf("got identity %+v, want the SANs added by a and b", id)
+	}
+	if held, ok := holder.Identity(); !ok || held != id {
+		t.Errorf("holder has %+v, want %+v", held, id)
+	}
+
+	ran = nil
+	if _, err := Chain(add("a", denied), add("b", nil))(context.Background(), "/s/M"); err != denied {
+		t.Errorf("got error %v, want %v", err, denied)
+	}
+	if strings.Join(ran, ",") != "a" {
+		t.Errorf("ran %q, want only a", ran)
+	}
+}
+
+func TestIdentityPrincipals(t *testing.T) {
+	id := &Identity{CommonName: "client", SANs: []string{"a.example.com", "10.0.0.1"}, Subject: "svc"}
+	want := "cn:client,san:a.example.com,san:10.0.0.1,token:svc"
+	if got := strings.Join(id.Principals(), ","); got != want {
+		t.Errorf("Principals() = %q, want %q", got, want)
+	}
+}
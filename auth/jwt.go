@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// JWTAuthenticator requires every call to carry a bearer token in its
+// "authorization" metadata, signed by one of the keys of a JWKS file and
+// issued by and for the expected parties. Health checks don't need one, as
+// load balancers probing the server have no way to get tokens.
+type JWTAuthenticator struct {
+	keys     map[string]crypto.PublicKey // by key id
+	issuer   string
+	audience string
+}
+
+// jwk is a JSON Web Key, with the fields needed for RSA, EC and Ed25519
+// public keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWTAuthenticator loads the signing keys from the JWKS file. Tokens must
+// have the given issuer and audience, which are both required.
+func NewJWTAuthenticator(jwksFile, issuer, audience string) (*JWTAuthenticator, error) {
+	if issuer == "" || audience == "" {
+		return nil, fmt.Errorf("the issuer and audience of the tokens are required")
+	}
+	data, err := os.ReadFile(jwksFile)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", jwksFile, err)
+	}
+	a := &JWTAuthenticator{keys: make(map[string]crypto.PublicKey), issuer: issuer, audience: audience}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("%s: key #%d: %w", jwksFile, i, err)
+		}
+		a.keys[k.Kid] = key
+	}
+	if len(a.keys) == 0 {
+		return nil, fmt.Errorf("%s: no signing keys found", jwksFile)
+	}
+	return a, nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point isn't on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// keyFunc returns the key that signed the token, chosen by its key id. The
+// key id may be omitted when the JWKS file has a single key.
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := a.keys[kid]
+	if !ok && kid == "" && len(a.keys) == 1 {
+		for _, k := range a.keys {
+			key, ok = k, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	var matches bool
+	switch key.(type) {
+	case *rsa.PublicKey:
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			matches = true
+		}
+	case *ecdsa.PublicKey:
+		_, matches = token.Method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, matches = token.Method.(*jwt.SigningMethodEd25519)
+	}
+	if !matches {
+		return nil, fmt.Errorf("signing method %s doesn't match key %q", token.Method.Alg(), kid)
+	}
+	return key, nil
+}
+
+// authenticate validates the bearer token in the metadata of ctx, returning
+// ctx carrying the subject of the token in the caller identity.
+func (a *JWTAuthenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	// The scheme name is case insensitive, see RFC 6750 and RFC 7235.
+	scheme, raw, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, status.Error(codes.Unauthenticated, "authorization isn't a bearer token")
+	}
+	raw = strings.TrimSpace(raw)
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+		jwt.WithIssuer(a.issuer),
+		jwt.WithAudience(a.audience),
+	}
+	claims := &jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(raw, claims, a.keyFunc, opts...); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid bearer token: %v", err)
+	}
+	if claims.Subject == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token: missing subject")
+	}
+
+	id := &Identity{}
+	if peerID, ok := IdentityFromContext(ctx); ok {
+		*id = *peerID
+	}
+	id.Subject = claims.Subject
+	return withIdentity(ctx, id), nil
+}
+
+// Authorize rejects the calls without a valid bearer token with
+// Unauthenticated, returning ctx carrying the subject of the token in the
+// identity of the caller. Health checks are always allowed.
+func (a *JWTAuthenticator) Authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if isHealthCheck(fullMethod) {
+		return ctx, nil
+	}
+	return a.authenticate(ctx)
+}
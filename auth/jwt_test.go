@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// writeJWKS writes a JWKS file with the public key of key, under the key id
+// kid, returning its path.
+func writeJWKS(t *testing.T, key *ecdsa.PrivateKey, kid string) string {
+	t.Helper()
+	coord := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string][]jwk{"keys": {{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   coord(key.X.FillBytes(make([]byte, 32))),
+		Y:   coord(key.Y.FillBytes(make([]byte, 32))),
+	}}}
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signToken(t *testing.T, key *ecdsa.PrivateKey, kid string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestJWTAuthenticatorAuthorize(t *testing.T) {
+	key, otherKey := newECDSAKey(t), newECDSAKey(t)
+	tokens, err := NewJWTAuthenticator(writeJWKS(t, key, "k1"), "jwtgen", "personguide")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	valid := jwt.RegisteredClaims{
+		Subject:   "test-client1",
+		Issuer:    "jwtgen",
+		Audience:  jwt.ClaimStrings{"personguide"},
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+	with := func(change func(*jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		c := valid
+		change(&c)
+		return c
+	}
+	tests := []struct {
+		name          string
+		authorization []string
+		want          codes.Code
+	}{
+		{"valid token", []string{"Bearer " + signToken(t, key, "k1", valid)}, codes.OK},
+		{"lower case scheme", []string{"bearer " + signToken(t, key, "k1", valid)}, codes.OK},
+		{"upper case scheme", []string{"BEARER " + signToken(t, key, "k1", valid)}, codes.OK},
+		{"missing", nil, codes.Unauthenticated},
+		{"basic scheme", []string{"Basic dXNlcjpwYXNz"}, codes.Unauthenticated},
+		{"no token", []string{"Bearer"}, codes.Unauthenticated},
+		{"unknown key id", []string{"Bearer " + signToken(t, key, "k2", valid)}, codes.Unauthenticated},
+		{"signed by another key", []string{"Bearer " + signToken(t, otherKey, "k1", valid)}, codes.Unauthenticated},
+		{"wrong issuer", []string{"Bearer " + signToken(t, key, "k1", with(func(c *jwt.RegisteredClaims) {
+			c.Issuer = "someone"
+		}))}, codes.Unauthenticated},
+		{"no issuer", []string{"Bearer " + signToken(t, key, "k1", with(func(c *jwt.RegisteredClaims) {
+			c.Issuer = ""
+		}))}, codes.Unauthenticated},
+		{"wrong audience", []string{"Bearer " + signToken(t, key, "k1", with(func(c *jwt.RegisteredClaims) {
+			c.Audience = jwt.ClaimStrings{"other"}
+		}))}, codes.Unauthenticated},
+		{"expired", []string{"Bearer " + signToken(t, key, "k1", with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour))
+		}))}, codes.Unauthenticated},
+		{"no expiry", []string{"Bearer " + signToken(t, key, "k1", with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = nil
+		}))}, codes.Unauthenticated},
+		{"no subject", []string{"Bearer " + signToken(t, key, "k1", with(func(c *jwt.RegisteredClaims) {
+			c.Subject = ""
+		}))}, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.MD{}
+			if tt.authorization != nil {
+				md.Set("authorization", tt.authorization...)
+			}
+			ctx, err := tokens.Authorize(metadata.NewIncomingContext(context.Background(), md), "/personguide.PersonGuide/GetPhone")
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("got code %v (%v), want %v", got, err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			id, ok := IdentityFromContext(ctx)
+			if !ok || id.Subject != valid.Subject {
+				t.Errorf("got identity %+v, want subject %q", id, valid.Subject)
+			}
+		})
+	}
+}
+
+func TestNewJWTAuthenticatorRequiresIssuerAndAudience(t *testing.T) {
+	jwks := writeJWKS(t, newECDSAKey(t), "k1")
+	for _, tt := range []struct{ issuer, audience string }{
+		{"", "personguide"},
+		{"jwtgen", ""},
+	} {
+		if _, err := NewJWTAuthenticator(jwks, tt.issuer, tt.audience); err == nil {
+			t.Errorf("NewJWTAuthenticator(%q, %q) succeeded, want an error", tt.issuer, tt.audience)
+		}
+	}
+}
+
+func TestJWTAuthenticatorAllowsHealthChecks(t *testing.T) {
+	tokens, err := NewJWTAuthenticator(writeJWKS(t, newECDSAKey(t), "k1"), "jwtgen", "personguide")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Authorize(context.Background(), "/grpc.health.v1.Health/Check"); err != nil {
+		t.Errorf("health check without a token: got %v, want no error", err)
+	}
+}
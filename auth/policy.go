@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Policy decides which callers may call which methods. A call is
+// allowed if any rule matches both one of the principals of the caller and
+// the method. Everything else is denied, except health checks, which are
+// always allowed so load balancers can probe the server.
+//
+// Policies are read from a JSON file like:
+//
+//	{
+//	  "rules": [
+//	    {"principals": ["cn:test-client1"], "methods": ["*"]},
+//	    {"principals": ["san:*.partner.example.com"], "methods": ["GetPhone", "ListPersons"]}
+//	  ]
+//	}
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule allows the principals to call the methods.
+//
+// Principals are "cn:<common name>" or "san:<subject alternative name>" of
+// the client certificate, "token:<subject>" of the bearer token, or "*" for
+// every caller, authenticated or not.
+// Methods are full method names such as "/personguide.PersonGuide/GetPhone",
+// or just the method name for the methods of the PersonGuide service.
+// Both may use the wildcards of path.Match.
+type Rule struct {
+	Principals []string `json:"principals"`
+	Methods    []string `json:"methods"`
+}
+
+// LoadPolicy reads and validates the policy in the given file.
+func LoadPolicy(filePath string) (*Policy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	for i, rule := range policy.Rules {
+		if len(rule.Principals) == 0 || len(rule.Methods) == 0 {
+			return nil, fmt.Errorf("%s: rule #%d: principals and methods are required", filePath, i)
+		}
+		for j, m := range rule.Methods {
+			rule.Methods[j] = fullMethodPattern(m)
+		}
+		for _, patterns := range [][]string{rule.Principals, rule.Methods} {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return nil, fmt.Errorf("%s: rule #%d: invalid pattern %q: %w", filePath, i, pattern, err)
+				}
+			}
+		}
+	}
+	return policy, nil
+}
+
+// fullMethodPattern turns a bare method name into the full name of the
+// method in the PersonGuide service.
+func fullMethodPattern(method string) string {
+	if method == "*" || strings.HasPrefix(method, "/") {
+		return method
+	}
+	return "/personguide.PersonGuide/" + method
+}
+
+// Allowed reports whether a caller with the given principals may call the
+// method.
+func (p *Policy) Allowed(principals []string, fullMethod string) bool {
+	for _, rule := range p.Rules {
+		if matchAny(rule.Methods, fullMethod) && matchPrincipals(rule.Principals, principals) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPrincipals(patterns, principals []string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+	}
+	for _, principal := range principals {
+		if matchAny(patterns, principal) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize rejects the calls of callers not allowed to call the method
+// with PermissionDenied. The caller is the identity in ctx, set by the
+// authorizers chained before it. Health checks are always allowed.
+func (p *Policy) Authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if isHealthCheck(fullMethod) {
+		return ctx, nil
+	}
+	var principals []string
+	who := "unauthenticated caller"
+	if id, ok := IdentityFromContext(ctx); ok {
+		principals = id.Principals()
+		who = strings.Join(principals, ", ")
+	}
+	if !p.Allowed(principals, fullMethod) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", who, fullMethod)
+	}
+	return ctx, nil
+}
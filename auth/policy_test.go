@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicyAllowed(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Principals: []string{"cn:admin"}, Methods: []string{"*"}},
+		{Principals: []string{"cn:read-only-*", "san:*.partner.example.com"}, Methods: []string{
+			fullMethodPattern("GetPhone"), fullMethodPattern("ListPersons"),
+		}},
+		{Principals: []string{"*"}, Methods: []string{"/grpc.reflection.v1.ServerReflection/*"}},
+	}}
+	tests := []struct {
+		name       string
+		principals []string
+		method     string
+		want       bool
+	}{
+		{"any method for admin", []string{"cn:admin"}, "/personguide.PersonGuide/RecordPersons", true},
+		{"read method for read-only", []string{"cn:read-only-1"}, "/personguide.PersonGuide/GetPhone", true},
+		{"write method for read-only", []string{"cn:read-only-1"}, "/personguide.PersonGuide/RecordPersons", false},
+		{"read method for partner SAN", []string{"cn:other", "san:api.partner.example.com"}, "/personguide.PersonGuide/ListPersons", true},
+		{"SAN pattern needs a subdomain", []string{"san:partner.example.com"}, "/personguide.PersonGuide/ListPersons", false},
+		{"token subject not in any rule", []string{"token:admin"}, "/personguide.PersonGuide/GetPhone", false},
+		{"method of another service", []string{"cn:read-only-1"}, "/other.Service/GetPhone", false},
+		{"wildcard principal for unauthenticated", nil, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", true},
+		{"unauthenticated", nil, "/personguide.PersonGuide/GetPhone", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allowed(tt.principals, tt.method); got != tt.want {
+				t.Errorf("allowed(%q, %q) = %v, want %v", tt.principals, tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Principals: []string{"cn:admin", "token:admin"}, Methods: []string{"*"}},
+	}}
+	tests := []struct {
+		name   string
+		caller *Identity
+		method string
+		want   codes.Code
+	}{
+		{"allowed certificate", &Identity{CommonName: "admin"}, "/personguide.PersonGuide/GetPhone", codes.OK},
+		{"allowed token", &Identity{CommonName: "other", Subject: "admin"}, "/personguide.PersonGuide/GetPhone", codes.OK},
+		{"denied", &Identity{CommonName: "other"}, "/personguide.PersonGuide/GetPhone", codes.PermissionDenied},
+		{"unauthenticated", nil, "/personguide.PersonGuide/GetPhone", codes.PermissionDenied},
+		{"health check", nil, "/grpc.health.v1.Health/Check", codes.OK},
+		{"health watch", &Identity{CommonName: "other"}, "/grpc.health.v1.Health/Watch", codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = withIdentity(ctx, tt.caller)
+			}
+			_, err := policy.Authorize(ctx, tt.method)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("got code %v (%v), want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Package main implements a command that creates the x509 certificates and
+// keys used by the person guide client and server, without needing openssl.
+//
+// It writes a server CA, a client CA, a server certificate signed by the
+// server CA and a client certificate signed by the client CA, with the file
+// names the client and server look for in data/x509 by default.
+//
+// Run it with -revoke to add a client certificate to the revocation list of
+// the client CA, which the server reads with -crl, instead.
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackgris/go-grpc-communication/data"
+)
+
+var (
+	outDir       = flag.String("out_dir", "", "The directory the files are written to, data/x509 if empty")
+	keyType      = flag.String("key_type", "rsa", "The type of the keys: rsa, ecdsa or ed25519")
+	rsaBits      = flag.Int("rsa_bits", 4096, "The size of RSA keys")
+	caLifetime   = flag.Duration("ca_lifetime", 10*365*24*time.Hour, "How long the CA certificates are valid")
+	certLifetime = flag.Duration("cert_lifetime", 10*365*24*time.Hour, "How long the server and client certificates are valid")
+	serverCN     = flag.String("server_cn", "test-server1", "The common name of the server certificate")
+	serverSANs   = flag.String("server_sans", "*.test.example.com", "Comma separated DNS names, IP addresses, URIs or emails of the server certificate")
+	clientCN     = flag.String("client_cn", "test-client1", "The common name of the client certificate")
+	clientSANs   = flag.String("client_sans", "", "Comma separated DNS names, IP addresses, URIs or emails of the client certificate")
+	revoke       = flag.String("revoke", "", "A client cert file to add to the CRL of the client CA, instead of creating certificates")
+	revokeReason = flag.Int("revoke_reason", 0, "The RFC 5280 reason code of the revocation, 1 is keyCompromise")
+	crlLifetime  = flag.Duration("crl_lifetime", 7*24*time.Hour, "How long until the CRL should be updated")
+)
+
+// issued is a certificate along with its private key.
+type issued struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func main() {
+	flag.Parse()
+	if *outDir == "" {
+		*outDir = data.Path("x509")
+	}
+	if *revoke != "" {
+		if err := revokeCert(*revoke); err != nil {
+			log.Fatalf("Failed to revoke %s: %v", *revoke, err)
+		}
+		return
+	}
+
+	serverCA, err := create("ca", caTemplate("test-server_ca"), nil)
+	if err != nil {
+		log.Fatalf("Failed to create server CA: %v", err)
+	}
+	clientCA, err := create("client_ca", caTemplate("test-client_ca"), nil)
+	if err != nil {
+		log.Fatalf("Failed to create client CA: %v", err)
+	}
+
+	server, err := leafTemplate(*serverCN, *serverSANs, x509.ExtKeyUsageServerAuth)
+	if err != nil {
+		log.Fatalf("Invalid server certificate: %v", err)
+	}
+	if _, err := create("server", server, serverCA); err != nil {
+		log.Fatalf("Failed to create server certificate: %v", err)
+	}
+	client, err := leafTemplate(*clientCN, *clientSANs, x509.ExtKeyUsageClientAuth)
+	if err != nil {
+		log.Fatalf("Invalid client certificate: %v", err)
+	}
+	if _, err := create("client", client, clientCA); err != nil {
+		log.Fatalf("Failed to create client certificate: %v", err)
+	}
+}
+
+func subject(cn string) pkix.Name {
+	return pkix.Name{
+		Country:      []string{"US"},
+		Province:     []string{"CA"},
+		Locality:     []string{"SVL"},
+		Organization: []string{"gRPC"},
+		CommonName:   cn,
+	}
+}
+
+func caTemplate(cn string) *x509.Certificate {
+	now := time.Now()
+	return &x509.Certificate{
+		Subject:               subject(cn),
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(*caLifetime),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+}
+
+// leafTemplate returns the template of a certificate with the given common
+// name and comma separated subject alternative names.
+func leafTemplate(cn, sans string, usage x509.ExtKeyUsage) (*x509.Certificate, error) {
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		Subject:               subject(cn),
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(*certLifetime),
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+	}
+	for _, san := range strings.Split(sans, ",") {
+		san = strings.TrimSpace(san)
+		switch {
+		case san == "":
+		case net.ParseIP(san) != nil:
+			tmpl.IPAddresses = append(tmpl.IPAddresses, net.ParseIP(san))
+		case strings.Contains(san, "://"):
+			u, err := url.Parse(san)
+			if err != nil {
+				return nil, err
+			}
+			tmpl.URIs = append(tmpl.URIs, u)
+		case strings.Contains(san, "@"):
+			tmpl.EmailAddresses = append(tmpl.EmailAddresses, san)
+		default:
+			tmpl.DNSNames = append(tmpl.DNSNames, san)
+		}
+	}
+	return tmpl, nil
+}
+
+// create makes a new key and a certificate for it from tmpl, signed by
+// parent or self-signed if parent is nil, and writes them to
+// <name>_cert.pem and <name>_key.pem.
+func create(name string, tmpl *x509.Certificate, parent *issued) (*issued, error) {
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(*rsa.PrivateKey); ok && !tmpl.IsCA {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	tmpl.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	signer, signerCert := key, tmpl
+	if parent != nil {
+		signer, signerCert = parent.key, parent.cert
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signerCert, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	certFile := filepath.Join(*outDir, name+"_cert.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return nil, err
+	}
+	keyFile := filepath.Join(*outDir, name+"_key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, err
+	}
+	log.Printf("Wrote %s and %s, valid until %v", certFile, keyFile, cert.NotAfter)
+	return &issued{cert: cert, key: key}, nil
+}
+
+func generateKey() (crypto.Signer, error) {
+	switch *keyType {
+	case "rsa":
+		return rsa.GenerateKey(rand.Reader, *rsaBits)
+	case "ecdsa":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed25519":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return nil, fmt.Errorf("unknown key type %q, must be one of rsa, ecdsa or ed25519", *keyType)
+	}
+}
+
+// revokeCert adds the certificate in certFile to client_ca.crl, signed by the
+// client CA. The certificates already revoked in it are kept.
+func revokeCert(certFile string) error {
+	cert, err := readCert(certFile)
+	if err != nil {
+		return err
+	}
+	caPEM, err := os.ReadFile(filepath.Join(*outDir, "client_ca_cert.pem"))
+	if err != nil {
+		return err
+	}
+	caKeyPEM, err := os.ReadFile(filepath.Join(*outDir, "client_ca_key.pem"))
+	if err != nil {
+		return err
+	}
+	ca, err := tls.X509KeyPair(caPEM, caKeyPEM)
+	if err != nil {
+		return err
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	crlFile := filepath.Join(*outDir, "client_ca.crl")
+	tmpl := &x509.RevocationList{Number: big.NewInt(1)}
+	if b, err := os.ReadFile(crlFile); err == nil {
+		block, _ := pem.Decode(b)
+		if block == nil {
+			return fmt.Errorf("%s: no PEM encoded CRL found", crlFile)
+		}
+		old, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("%s: %w", crlFile, err)
+		}
+		tmpl.RevokedCertificateEntries = old.RevokedCertificateEntries
+		tmpl.Number = new(big.Int).Add(old.Number, big.NewInt(1))
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	now := time.Now()
+	tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+		SerialNumber:   cert.SerialNumber,
+		RevocationTime: now,
+		ReasonCode:     *revokeReason,
+	})
+	tmpl.ThisUpdate = now
+	tmpl.NextUpdate = now.Add(*crlLifetime)
+
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, caCert, ca.PrivateKey.(crypto.Signer))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0644); err != nil {
+		return err
+	}
+	log.Printf("Revoked %q (serial %s) in %s", cert.Subject, cert.SerialNumber, crlFile)
+	return nil
+}
+
+func readCert(certFile string) (*x509.Certificate, error) {
+	b, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s: no PEM encoded certificate found", certFile)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,157 @@
+// Package certwatch provides gRPC transport credentials built from
+// certificate files, which are built again whenever the files change, so
+// certificates can be rotated without restarting the process.
+//
+// New handshakes use the last credentials built, while established
+// connections and streams keep the ones they were created with.
+package certwatch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// LoadFunc builds the credentials from the certificate files. It also returns
+// when the certificates expire, which is logged on every reload.
+type LoadFunc func() (credentials.TransportCredentials, time.Time, error)
+
+// Credentials are transport credentials that delegate to the last ones built
+// by a LoadFunc.
+type Credentials struct {
+	*watcher
+}
+
+type watcher struct {
+	load  LoadFunc
+	files []string
+
+	creds atomic.Pointer[credentials.TransportCredentials]
+	mods  map[string]time.Time // modification time of files, only used by poll
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// New loads the credentials, and if interval is positive, checks the files
+// every interval, loading the credentials again when any of them changed.
+// For directories, the files in them are checked, so adding, removing or
+// changing any of them counts as a change.
+// If loading fails, the previous credentials are kept and the error logged.
+func New(load LoadFunc, files []string, interval time.Duration) (*Credentials, error) {
+	w := &watcher{load: load, files: files, mods: modTimes(files), stop: make(chan struct{})}
+	creds, expiry, err := load()
+	if err != nil {
+		return nil, err
+	}
+	w.creds.Store(&creds)
+	slog.Debug("Loaded certificates", "files", files, "expiry", expiry)
+	if interval > 0 {
+		go w.watch(interval)
+	}
+	return &Credentials{w}, nil
+}
+
+func (w *watcher) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll loads the credentials again if the modification time of any file
+// changed.
+func (w *watcher) poll() {
+	mods := modTimes(w.files)
+	changed := len(mods) != len(w.mods)
+	for f, t := range mods {
+		if !t.Equal(w.mods[f]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	creds, expiry, err := w.load()
+	if err != nil {
+		// Files are often written one at a time, retry on the next poll.
+		slog.Warn("Failed to reload certificates, keeping the previous ones", "files", w.files, "error", err)
+		return
+	}
+	w.mods = mods
+	w.creds.Store(&creds)
+	slog.Info("Reloaded certificates", "files", w.files, "expiry", expiry)
+}
+
+func modTimes(files []string) map[string]time.Time {
+	mods := make(map[string]time.Time, len(files))
+	for _, f := range files {
+		// A missing file gets the zero time, so it's seen as changed when it
+		// comes back.
+		info, err := os.Stat(f)
+		if err != nil {
+			mods[f] = time.Time{}
+			continue
+		}
+		mods[f] = info.ModTime()
+		if !info.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(f)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if info, err := e.Info(); err == nil {
+				mods[filepath.Join(f, e.Name())] = info.ModTime()
+			}
+		}
+	}
+	return mods
+}
+
+// Close stops watching the files. The credentials can still be used.
+func (c *Credentials) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *Credentials) current() credentials.TransportCredentials {
+	return *c.creds.Load()
+}
+
+func (c *Credentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (c *Credentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ServerHandshake(conn)
+}
+
+func (c *Credentials) Info() credentials.ProtocolInfo {
+	return c.current().Info()
+}
+
+// Clone returns credentials sharing the same watched files.
+func (c *Credentials) Clone() credentials.TransportCredentials {
+	return &Credentials{c.watcher}
+}
+
+// OverrideServerName isn't supported, the server name must be set by the
+// LoadFunc.
+func (c *Credentials) OverrideServerName(string) error {
+	return errors.New("certwatch: OverrideServerName is not supported")
+}
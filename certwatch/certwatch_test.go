@@ -0,0 +1,133 @@
+package certwatch
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// writeCert writes a self-signed certificate with the common name, and its
+// key, setting the modification time of both files to mod.
+func writeCert(t *testing.T, certFile, keyFile, commonName string, mod time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), mod)
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), mod)
+}
+
+func writeFile(t *testing.T, name string, data []byte, mod time.Time) {
+	t.Helper()
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// The modification time is set, as writes in a row can get the same one.
+	if err := os.Chtimes(name, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedName returns the common name of the certificate of a server
+// handshake made with creds.
+func servedName(t *testing.T, creds credentials.TransportCredentials) string {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	go creds.ServerHandshake(serverConn)
+
+	conn := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true})
+	if err := conn.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestPollReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	start := time.Now().Add(-time.Hour)
+	writeCert(t, certFile, keyFile, "first", start)
+
+	load := func() (credentials.TransportCredentials, time.Time, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}}), time.Time{}, nil
+	}
+	creds, err := New(load, []string{certFile, keyFile}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer creds.Close()
+	clone := creds.Clone()
+
+	creds.poll()
+	if got := servedName(t, creds); got != "first" {
+		t.Fatalf("served %q before the files changed, want first", got)
+	}
+
+	writeCert(t, certFile, keyFile, "second", start.Add(time.Minute))
+	creds.poll()
+	if got := servedName(t, creds); got != "second" {
+		t.Errorf("served %q after the files changed, want second", got)
+	}
+	if got := servedName(t, clone); got != "second" {
+		t.Errorf("clone served %q after the files changed, want second", got)
+	}
+
+	// A key not matching the certificate, as while the files are being
+	// written one at a time.
+	writeCert(t, certFile, filepath.Join(dir, "other.key"), "third", start.Add(2*time.Minute))
+	creds.poll()
+	if got := servedName(t, creds); got != "second" {
+		t.Errorf("served %q after a failed reload, want the previous second", got)
+	}
+
+	// The failed reload is retried on the next poll.
+	writeCert(t, certFile, keyFile, "third", start.Add(3*time.Minute))
+	creds.poll()
+	if got := servedName(t, creds); got != "third" {
+		t.Errorf("served %q after the files were fixed, want third", got)
+	}
+}
+
+func TestNewFailsIfLoadFails(t *testing.T) {
+	load := func() (credentials.TransportCredentials, time.Time, error) {
+		_, err := tls.LoadX509KeyPair("missing.crt", "missing.key")
+		return nil, time.Time{}, err
+	}
+	if _, err := New(load, []string{"missing.crt", "missing.key"}, time.Minute); err == nil {
+		t.Error("New succeeded, want the error of load")
+	}
+}
@@ -1,151 +1,382 @@
-// Package main implements a simple gRPC client that demonstrates how to use gRPC-Go libraries
-// to perform unary, client streaming, server streaming and full duplex RPCs.
+// Package main implements a command line client of the person guide service,
+// whose definition can be found in personguide/person_guide.proto.
 //
-// It interacts with the person guide service whose definition can be found in personguide/person_guide.proto.
+// Every RPC has a command, reading its input from flags, files or stdin and
+// printing the responses to stdout, one JSON object per line:
+//
+//	client [flags] <command> [command flags]
+//
+// Run it without a command to see the list of commands. The demo command
+// performs unary, client streaming, server streaming and full duplex RPCs
+// with example data.
 package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"log"
+	"os"
 	"time"
 
+	"github.com/jackgris/go-grpc-communication/certwatch"
+	"github.com/jackgris/go-grpc-communication/config"
 	"github.com/jackgris/go-grpc-communication/data"
 	pb "github.com/jackgris/go-grpc-communication/personguide"
+	"github.com/jackgris/go-grpc-communication/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
-var (
-	tls                = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
-	caFile             = flag.String("ca_file", "", "The file containing the CA root cert file")
-	serverAddr         = flag.String("addr", "localhost:50051", "The server address in the format of host:port")
-	serverHostOverride = flag.String("server_host_override", "x.test.example.com", "The server name used to verify the hostname returned by the TLS handshake")
-)
-
-// printPhone get the phone from the person with send.
-func printPhone(client pb.PersonGuideClient, person *pb.Person) {
-	log.Printf("Getting phone from person %s", person.GetName())
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	phone, err := client.GetPhone(ctx, person)
+// printPhone prints the phone of the person of the request.
+func printPhone(ctx context.Context, client pb.PersonGuideClient, req *pb.GetPhoneRequest) error {
+	phone, err := client.GetPhone(ctx, req)
 	if err != nil {
-		log.Fatalf("client.GetPhone failed: %v", err)
+		return fmt.Errorf("client.GetPhone failed: %w", err)
 	}
-	log.Println(phone)
+	return printMessage(phone)
 }
 
 // printPersons lists all the persons in same adress.
-func printPersons(client pb.PersonGuideClient, adress *pb.Adress) {
-	log.Printf("Looking for persons in adress %s", adress.GetName())
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func printPersons(ctx context.Context, client pb.PersonGuideClient, adress *pb.Adress) error {
 	stream, err := client.ListPersons(ctx, adress)
 	if err != nil {
-		log.Fatalf("client.ListPersons failed: %v", err)
+		return fmt.Errorf("client.ListPersons failed: %w", err)
 	}
 	for {
 		person, err := stream.Recv()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			log.Fatalf("client.ListPersons failed: %v", err)
+			return fmt.Errorf("client.ListPersons failed: %w", err)
+		}
+		if err := printMessage(person); err != nil {
+			return err
 		}
-		log.Printf("Person: name: %s, email:%s, Id: %d\n", person.GetName(),
-			person.GetEmail(), person.GetId())
 	}
 }
 
 // runRecordPersons sends a sequence of persons to server and expects to get a summary of all persons from server.
-func runRecordPersons(client pb.PersonGuideClient) {
-	log.Printf("Traversing %d persons.", len(persons))
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func runRecordPersons(ctx context.Context, client pb.PersonGuideClient, persons []*pb.Person) error {
 	stream, err := client.RecordPersons(ctx)
 	if err != nil {
-		log.Fatalf("client.RecordPersons failed: %v", err)
+		return fmt.Errorf("client.RecordPersons failed: %w", err)
 	}
-	for p := range persons {
-		if err := stream.Send(&persons[p]); err != nil {
-			log.Fatalf("client.RecordPersons: stream.Send(%v) failed: %v", &persons[p], err)
+	for _, p := range persons {
+		if err := stream.Send(p); err != nil {
+			// The reason of the failure is returned by CloseAndRecv.
+			break
 		}
 	}
 	reply, err := stream.CloseAndRecv()
 	if err != nil {
-		log.Fatalf("client.RecordPersons failed: %v", err)
+		return fmt.Errorf("client.RecordPersons failed: %w", err)
 	}
-	log.Printf("AdressBook summary: %v", reply)
+	return printMessage(reply)
 }
 
 // runRoutePhones receives a sequence of route phones, while sending a list of persons.
-func runRoutePhones(client pb.PersonGuideClient) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func runRoutePhones(ctx context.Context, client pb.PersonGuideClient, persons []*pb.Person) error {
 	stream, err := client.RoutePhones(ctx)
 	if err != nil {
-		log.Fatalf("client.RoutePhones failed: %v", err)
+		return fmt.Errorf("client.RoutePhones failed: %w", err)
 	}
-	waitc := make(chan struct{})
+	errc := make(chan error, 1)
 	go func() {
 		for {
 			phone, err := stream.Recv()
 			if err == io.EOF {
-				// read done.
-				close(waitc)
+				errc <- nil
 				return
 			}
 			if err != nil {
-				log.Fatalf("client.RoutePhones failed: %v", err)
+				errc <- fmt.Errorf("client.RoutePhones failed: %w", err)
+				return
+			}
+			if err := printMessage(phone); err != nil {
+				errc <- err
+				return
 			}
-			log.Printf("Got phone %s type %v", phone.Number, phone.Type)
 		}
 	}()
-	for p := range persons {
-		if err := stream.Send(&persons[p]); err != nil {
-			log.Fatalf("client.RoutePhones: stream.Send(%v) failed: %v", &persons[p], err)
+	for _, p := range persons {
+		if err := stream.Send(p); err != nil {
+			// The reason of the failure is returned by Recv.
+			break
 		}
 	}
-	// For now we don't check errors, don't do this in production
 	_ = stream.CloseSend()
-	<-waitc
+	return <-errc
 }
 
-func main() {
-	flag.Parse()
-	var opts []grpc.DialOption
-	if *tls {
-		if *caFile == "" {
-			*caFile = data.Path("x509/ca_cert.pem")
+// printPersonsByPhone prints the persons having the given phone number.
+func printPersonsByPhone(ctx context.Context, client pb.PersonGuideClient, phone *pb.PhoneNumber) error {
+	book, err := client.FindByPhone(ctx, phone)
+	if err != nil {
+		return fmt.Errorf("client.FindByPhone failed: %w", err)
+	}
+	for _, person := range book.People {
+		if err := printMessage(person); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runIngestPersons sends persons to be saved, printing the outcome of each
+// one and the totals. It fails with errRejected if any person was rejected.
+func runIngestPersons(ctx context.Context, client pb.PersonGuideClient, persons []*pb.Person) error {
+	stream, err := client.IngestPersons(ctx)
+	if err != nil {
+		return fmt.Errorf("client.IngestPersons failed: %w", err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		var rejected int64
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				if rejected > 0 {
+					errc <- fmt.Errorf("%d persons: %w", rejected, errRejected)
+					return
+				}
+				errc <- nil
+				return
+			}
+			if err != nil {
+				errc <- fmt.Errorf("client.IngestPersons failed: %w", err)
+				return
+			}
+			if s := resp.GetSummary(); s != nil {
+				rejected = s.Rejected
+			}
+			if err := printMessage(resp); err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+	for _, p := range persons {
+		if err := stream.Send(p); err != nil {
+			// The reason of the failure is returned by Recv.
+			break
+		}
+	}
+	_ = stream.CloseSend()
+	return <-errc
+}
+
+// runPersonCRUD creates a person, updates its email, gets it back and deletes it,
+// printing the person after each step.
+func runPersonCRUD(ctx context.Context, client pb.PersonGuideClient, person *pb.Person) error {
+	created, err := client.CreatePerson(ctx, &pb.CreatePersonRequest{Person: person})
+	if err != nil {
+		return fmt.Errorf("client.CreatePerson failed: %w", err)
+	}
+	if err := printMessage(created); err != nil {
+		return err
+	}
+
+	created.Email = "new." + created.Email
+	updated, err := client.UpdatePerson(ctx, &pb.UpdatePersonRequest{
+		Person:     created,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	})
+	if err != nil {
+		return fmt.Errorf("client.UpdatePerson failed: %w", err)
+	}
+	if err := printMessage(updated); err != nil {
+		return err
+	}
+
+	got, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: updated.Id})
+	if err != nil {
+		return fmt.Errorf("client.GetPerson failed: %w", err)
+	}
+	if err := printMessage(got); err != nil {
+		return err
+	}
+
+	if _, err := client.DeletePerson(ctx, &pb.DeletePersonRequest{Id: got.Id}); err != nil {
+		return fmt.Errorf("client.DeletePerson failed: %w", err)
+	}
+	log.Printf("Deleted person %d", got.Id)
+	return nil
+}
+
+// runDemo calls every RPC with the example data.
+func runDemo(ctx context.Context, client pb.PersonGuideClient) error {
+	if err := runRecordPersons(ctx, client, persons); err != nil {
+		return err
+	}
+	if err := runRoutePhones(ctx, client, persons); err != nil {
+		return err
+	}
+	for _, p := range persons {
+		if err := printPhone(ctx, client, &pb.GetPhoneRequest{Id: p.Id, Type: pb.PhoneType_WORK.Enum()}); err != nil {
+			return err
+		}
+	}
+	if err := printPersons(ctx, client, &pb.Adress{Name: "my adress"}); err != nil {
+		return err
+	}
+	adress := &pb.Adress{Name: "Buenos Aires downtown", City: "buenos aires", PostalCode: "C10", Prefix: true}
+	if err := printPersons(ctx, client, adress); err != nil {
+		return err
+	}
+	if err := printPersonsByPhone(ctx, client, &pb.PhoneNumber{Number: "43-21"}); err != nil {
+		return err
+	}
+	if err := runPersonCRUD(ctx, client, &pb.Person{Name: "Nick", Email: "nick@gmail.com", Phones: phones}); err != nil {
+		return err
+	}
+	// Nobody is rejected on purpose, to show how the outcome is reported.
+	err := runIngestPersons(ctx, client, []*pb.Person{
+		{Name: "Juan", Id: 1, Email: "juan@gmail.com", Phones: phones},
+		{Name: "Laura", Id: 20, Email: "laura@gmail.com", Phones: phones},
+		{Name: "Nobody", Email: "nobody"},
+	})
+	if errors.Is(err, errRejected) {
+		return nil
+	}
+	return err
+}
+
+// checkHealth prints the serving status of the service, or of the whole
+// server if service is empty, failing with errNotServing unless it's serving.
+func checkHealth(ctx context.Context, conn *grpc.ClientConn, service string) error {
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	fmt.Println(resp.Status)
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return errNotServing
+	}
+	return nil
+}
+
+// dial connects to the server with the credentials given by the flags.
+func dial() (*grpc.ClientConn, error) {
+	// Sends the trace context to the server, and traces every call.
+	opts := []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+	if cfg.TLS || cfg.MTLS {
+		if cfg.CAFile == "" {
+			cfg.CAFile = data.Path("x509/ca_cert.pem")
 		}
-		creds, err := credentials.NewClientTLSFromFile(*caFile, *serverHostOverride)
+		files := []string{cfg.CAFile}
+		var clientCert, clientKey string // only presented with mTLS
+		if cfg.MTLS {
+			if cfg.CertFile == "" {
+				cfg.CertFile = data.Path("x509/client_cert.pem")
+			}
+			if cfg.KeyFile == "" {
+				cfg.KeyFile = data.Path("x509/client_key.pem")
+			}
+			clientCert, clientKey = cfg.CertFile, cfg.KeyFile
+			files = append(files, clientCert, clientKey)
+		}
+		load := func() (credentials.TransportCredentials, time.Time, error) {
+			return loadClientCredentials(cfg.CAFile, clientCert, clientKey, cfg.ServerHostOverride)
+		}
+		creds, err := certwatch.New(load, files, cfg.CertReloadInterval)
 		if err != nil {
-			log.Fatalf("Failed to create TLS credentials: %v", err)
+			return nil, fmt.Errorf("failed to create TLS credentials: %w", err)
 		}
 		opts = append(opts, grpc.WithTransportCredentials(creds))
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
-	conn, err := grpc.Dial(*serverAddr, opts...)
-	if err != nil {
-		log.Fatalf("fail to dial: %v", err)
+	if cfg.TokenFile != "" {
+		token, err := readToken(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token: %w", err)
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(token))
 	}
-	defer conn.Close()
-	client := pb.NewPersonGuideClient(conn)
 
-	runRecordPersons(client)
+	return grpc.Dial(cfg.Addr, opts...)
+}
 
-	runRoutePhones(client)
+func main() {
+	flag.Usage = usage
+	cfg.registerFlags(flag.CommandLine)
+	if err := config.Load(flag.CommandLine, os.Args[1:], envPrefix); err != nil {
+		log.Printf("Failed to load config: %v", err)
+		os.Exit(exitUsage)
+	}
+	if err := cfg.validate(); err != nil {
+		log.Printf("Invalid config: %v", err)
+		os.Exit(exitUsage)
+	}
+	cmd := findCommand(flag.Arg(0))
+	if cmd == nil {
+		if flag.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+		}
+		usage()
+		os.Exit(exitUsage)
+	}
 
-	for p := range persons {
-		printPhone(client, &persons[p])
+	if cmd.name == "config" {
+		err := cmd.run(context.Background(), nil, flag.Args()[1:])
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Printf("%s: %v", cmd.name, err)
+		}
+		os.Exit(exitCode(err))
+	}
+
+	// The format was checked by validate.
+	out, _ = newPrinter(cfg.Output, os.Stdout)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TraceExporter,
+		Endpoint:    cfg.OTLPEndpoint,
+		Insecure:    cfg.OTLPInsecure,
+		File:        cfg.TraceFile,
+		ServiceName: "personguide-client",
+	})
+	if err != nil {
+		log.Printf("Failed to set up tracing: %v", err)
+		os.Exit(exitUsage)
 	}
 
-	adress := pb.Adress{Name: "my adress"}
-	printPersons(client, &adress)
+	conn, err := dial()
+	if err != nil {
+		log.Printf("fail to dial: %v", err)
+		os.Exit(exitUnavailable)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	// The calls of a command share its trace.
+	ctx, span := otel.Tracer("github.com/jackgris/go-grpc-communication/client").Start(ctx, "client "+cmd.name)
+	err = cmd.run(ctx, conn, flag.Args()[1:])
+	if flushErr := out.flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		span.SetStatus(otelcodes.Error, err.Error())
+		log.Printf("%s: %v", cmd.name, err)
+	}
+	span.End()
+	cancel()
+	conn.Close()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Failed to flush spans: %v", err)
+	}
+	cancel()
+	os.Exit(exitCode(err))
 }
 
 // Example data
@@ -155,9 +386,13 @@ var phones = []*pb.PhoneNumber{
 	{Number: "4312", Type: pb.PhoneType_MOBILE},
 }
 
-var persons = []pb.Person{
-	{Name: "Juan", Id: 1, Email: "juan@gmail.com", Phones: phones},
-	{Name: "Gabriel", Id: 2, Email: "gabriel@gmail.com", Phones: phones},
+var persons = []*pb.Person{
+	{Name: "Juan", Id: 1, Email: "juan@gmail.com", Phones: phones, Addresses: []*pb.Address{
+		{Street: "Av. Corrientes 1234", City: "Buenos Aires", PostalCode: "C1043", Country: "AR", Type: pb.Address_HOME},
+	}},
+	{Name: "Gabriel", Id: 2, Email: "gabriel@gmail.com", Phones: phones, Addresses: []*pb.Address{
+		{Street: "Av. Santa Fe 800", City: "Buenos Aires", PostalCode: "C1059", Country: "AR", Type: pb.Address_WORK},
+	}},
 	{Name: "Albert", Id: 3, Email: "albert@gmail.com", Phones: phones},
 	{Name: "Mark", Id: 4, Email: "mark@gmail.com", Phones: phones},
 	{Name: "Brian", Id: 5, Email: "brian@gmail.com", Phones: phones},
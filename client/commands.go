@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Exit codes of the commands, so scripts can tell failures apart.
+const (
+	exitOK          = 0
+	exitFailure     = 1 // any other failure, also a health check not serving
+	exitUsage       = 2 // unknown command or invalid flags
+	exitInput       = 3 // the input couldn't be read or parsed
+	exitNotFound    = 4 // the person or phone doesn't exist
+	exitInvalid     = 5 // the server rejected the input
+	exitDenied      = 6 // missing or invalid credentials, or not allowed
+	exitUnavailable = 7 // the server couldn't be reached in time
+)
+
+var (
+	errRejected   = errors.New("rejected by the server")
+	errNotServing = errors.New("not serving")
+)
+
+// usageError is returned for invalid command flags or arguments.
+type usageError struct{ error }
+
+// inputError is returned when the input files can't be read or parsed.
+type inputError struct{ error }
+
+// exitCode returns the exit code for the error returned by a command.
+func exitCode(err error) int {
+	var (
+		usageErr *usageError
+		inputErr *inputError
+		grpcErr  interface{ GRPCStatus() *status.Status }
+	)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &inputErr):
+		return exitInput
+	case errors.Is(err, errRejected):
+		return exitInvalid
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitUnavailable
+	case errors.As(err, &grpcErr):
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.NotFound:
+			return exitNotFound
+		case codes.InvalidArgument, codes.AlreadyExists, codes.FailedPrecondition, codes.OutOfRange:
+			return exitInvalid
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return exitDenied
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return exitUnavailable
+		}
+	}
+	return exitFailure
+}
+
+// command is a subcommand of the client, run with the arguments following
+// its name.
+type command struct {
+	name string
+	args string // synopsis of the arguments
+	help string
+	run  func(ctx context.Context, conn *grpc.ClientConn, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	// Set in init, as the commands look themselves up for their usage.
+	commands = []*command{
+		{"get-phone", "--id N | --email E [--type HOME|WORK|MOBILE]", "Print a phone of a person", runGetPhone},
+		{"list", "[--address JSON] [--city C] [--postal_code P] [--prefix] [--region R] [--country C]", "Print the persons with an address matching the query", runList},
+		{"record", "[--file F]", "Save the persons in the file and print them", runRecord},
+		{"route-phones", "[--file F]", "Print the phones of the persons in the file", runRoutePhonesCommand},
+		{"ingest", "[--file F]", "Save the persons in the file, printing the outcome of each one", runIngest},
+		{"find-by-phone", "--number N", "Print the persons with the phone number", runFindByPhone},
+		{"get", "--id N", "Print a person", runGet},
+		{"create", "[--file F]", "Create the person in the file, assigning an id if it has none", runCreate},
+		{"update", "[--file F] [--mask FIELDS]", "Update the fields of the person in the file, all if no mask is given", runUpdate},
+		{"delete", "--id N", "Delete a person", runDelete},
+		{"health", "[SERVICE]", "Print the serving status of the server or one of its services", runHealth},
+		{"services", "", "Print the services of the server, found by reflection", runServices},
+		{"describe", "SYMBOL", "Print the definition of a service, method, message or enum", runDescribe},
+		{"invoke", "SERVICE/METHOD [JSON | -]", "Call a method with requests in JSON, found by reflection", runInvoke},
+		{"demo", "", "Call every RPC with example data", runDemoCommand},
+		{"config", "dump [--format yaml|toml]", "Print the settings, from the config file, variables and flags", runConfig},
+	}
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: %s [flags] <command> [command flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+	}
+	fmt.Fprintf(out, "\nFiles are JSON, either an array or a sequence of objects, \"-\" is stdin.\n")
+	fmt.Fprintf(out, "\nFlags, also set by the -config file and %s<FLAG> variables:\n", envPrefix)
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nExit codes: %d ok, %d failure, %d usage, %d invalid input, %d not found, %d rejected, %d denied, %d unavailable\n",
+		exitOK, exitFailure, exitUsage, exitInput, exitNotFound, exitInvalid, exitDenied, exitUnavailable)
+}
+
+// newFlagSet returns the flags of a command, whose errors are returned by
+// parseFlags instead of exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		c := findCommand(name)
+		fmt.Fprintf(fs.Output(), "usage: %s %s\n", os.Args[0], strings.TrimSpace(c.name+" "+c.args))
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args, allowing at most maxArgs arguments after the flags.
+func parseFlags(fs *flag.FlagSet, args []string, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return &usageError{err}
+	}
+	if fs.NArg() > maxArgs {
+		fs.Usage()
+		return &usageError{fmt.Errorf("unexpected arguments %q", fs.Args()[maxArgs:])}
+	}
+	return nil
+}
+
+// readPersons reads the persons in file, or stdin if it's "-", which is
+// either a JSON array of persons or a sequence of JSON persons.
+func readPersons(file string) ([]*pb.Person, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, &inputError{err}
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &inputError{err}
+	}
+
+	var records []json.RawMessage
+	if b = bytes.TrimSpace(b); bytes.HasPrefix(b, []byte("[")) {
+		if err := json.Unmarshal(b, &records); err != nil {
+			return nil, &inputError{fmt.Errorf("%s: %w", file, err)}
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, &inputError{fmt.Errorf("%s: %w", file, err)}
+			}
+			records = append(records, raw)
+		}
+	}
+
+	persons := make([]*pb.Person, len(records))
+	for i, raw := range records {
+		persons[i] = &pb.Person{}
+		if err := protojson.Unmarshal(raw, persons[i]); err != nil {
+			return nil, &inputError{fmt.Errorf("%s: person #%d: %w", file, i, err)}
+		}
+	}
+	return persons, nil
+}
+
+// readPerson reads a file with a single person.
+func readPerson(file string) (*pb.Person, error) {
+	persons, err := readPersons(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(persons) != 1 {
+		return nil, &inputError{fmt.Errorf("%s: found %d persons, want 1", file, len(persons))}
+	}
+	return persons[0], nil
+}
+
+func runGetPhone(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("get-phone")
+	id := fs.Int("id", 0, "The id of the person")
+	email := fs.String("email", "", "The email of the person, used when no id is given")
+	phoneType := fs.String("type", "", "The type of the phone, the first phone if empty")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *id == 0 && *email == "" {
+		return &usageError{errors.New("either --id or --email is required")}
+	}
+	req := &pb.GetPhoneRequest{Id: int32(*id), Email: *email}
+	if *phoneType != "" {
+		t, ok := pb.PhoneType_value[strings.ToUpper(*phoneType)]
+		if !ok {
+			return &usageError{fmt.Errorf("unknown phone type %q", *phoneType)}
+		}
+		req.Type = pb.PhoneType(t).Enum()
+	}
+	return printPhone(ctx, pb.NewPersonGuideClient(conn), req)
+}
+
+func runList(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("list")
+	query := fs.String("address", "", `The query as JSON, like {"city": "Buenos Aires"}, overridden by the other flags`)
+	city := fs.String("city", "", "The city of the address")
+	postalCode := fs.String("postal_code", "", "The postal code of the address")
+	prefix := fs.Bool("prefix", false, "Match addresses whose city and postal code start with the given ones")
+	region := fs.String("region", "", "The region of the address")
+	country := fs.String("country", "", "The country of the address")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	adress := &pb.Adress{}
+	if *query != "" {
+		if err := protojson.Unmarshal([]byte(*query), adress); err != nil {
+			return &usageError{fmt.Errorf("--address: %w", err)}
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "city":
+			adress.City = *city
+		case "postal_code":
+			adress.PostalCode = *postalCode
+		case "prefix":
+			adress.Prefix = *prefix
+		case "region":
+			adress.Region = *region
+		case "country":
+			adress.Country = *country
+		}
+	})
+	return printPersons(ctx, pb.NewPersonGuideClient(conn), adress)
+}
+
+func runRecord(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("record")
+	file := fs.String("file", "-", "The file with the persons")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	persons, err := readPersons(*file)
+	if err != nil {
+		return err
+	}
+	return runRecordPersons(ctx, pb.NewPersonGuideClient(conn), persons)
+}
+
+func runRoutePhonesCommand(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("route-phones")
+	file := fs.String("file", "-", "The file with the persons")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	persons, err := readPersons(*file)
+	if err != nil {
+		return err
+	}
+	return runRoutePhones(ctx, pb.NewPersonGuideClient(conn), persons)
+}
+
+func runIngest(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("ingest")
+	file := fs.String("file", "-", "The file with the persons")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	persons, err := readPersons(*file)
+	if err != nil {
+		return err
+	}
+	return runIngestPersons(ctx, pb.NewPersonGuideClient(conn), persons)
+}
+
+func runFindByPhone(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("find-by-phone")
+	number := fs.String("number", "", "The phone number, only its digits are compared")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *number == "" {
+		return &usageError{errors.New("--number is required")}
+	}
+	return printPersonsByPhone(ctx, pb.NewPersonGuideClient(conn), &pb.PhoneNumber{Number: *number})
+}
+
+func runGet(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("get")
+	id := fs.Int("id", 0, "The id of the person")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return &usageError{errors.New("--id is required")}
+	}
+	person, err := pb.NewPersonGuideClient(conn).GetPerson(ctx, &pb.GetPersonRequest{Id: int32(*id)})
+	if err != nil {
+		return fmt.Errorf("client.GetPerson failed: %w", err)
+	}
+	return printMessage(person)
+}
+
+func runCreate(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("create")
+	file := fs.String("file", "-", "The file with the person")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	person, err := readPerson(*file)
+	if err != nil {
+		return err
+	}
+	created, err := pb.NewPersonGuideClient(conn).CreatePerson(ctx, &pb.CreatePersonRequest{Person: person})
+	if err != nil {
+		return fmt.Errorf("client.CreatePerson failed: %w", err)
+	}
+	return printMessage(created)
+}
+
+func runUpdate(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("update")
+	file := fs.String("file", "-", "The file with the person, with the id of the one to update")
+	mask := fs.String("mask", "", "Comma separated fields to update, like email,phones")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	person, err := readPerson(*file)
+	if err != nil {
+		return err
+	}
+	req := &pb.UpdatePersonRequest{Person: person}
+	if *mask != "" {
+		req.UpdateMask = &fieldmaskpb.FieldMask{Paths: strings.Split(*mask, ",")}
+	}
+	updated, err := pb.NewPersonGuideClient(conn).UpdatePerson(ctx, req)
+	if err != nil {
+		return fmt.Errorf("client.UpdatePerson failed: %w", err)
+	}
+	return printMessage(updated)
+}
+
+func runDelete(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("delete")
+	id := fs.Int("id", 0, "The id of the person")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return &usageError{errors.New("--id is required")}
+	}
+	if _, err := pb.NewPersonGuideClient(conn).DeletePerson(ctx, &pb.DeletePersonRequest{Id: int32(*id)}); err != nil {
+		return fmt.Errorf("client.DeletePerson failed: %w", err)
+	}
+	return nil
+}
+
+func runHealth(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("health")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	return checkHealth(ctx, conn, fs.Arg(0))
+}
+
+func runDemoCommand(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	if err := parseFlags(newFlagSet("demo"), args, 0); err != nil {
+		return err
+	}
+	return runDemo(ctx, pb.NewPersonGuideClient(conn))
+}
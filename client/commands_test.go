@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{flag.ErrHelp, exitOK},
+		{errors.New("broken"), exitFailure},
+		{errNotServing, exitFailure},
+		{&usageError{errors.New("unknown flag")}, exitUsage},
+		{fmt.Errorf("get: %w", &usageError{errors.New("missing id")}), exitUsage},
+		{&inputError{os.ErrNotExist}, exitInput},
+		{fmt.Errorf("ingest: 2 persons %w", errRejected), exitInvalid},
+		{context.DeadlineExceeded, exitUnavailable},
+		{status.Error(codes.NotFound, "no person"), exitNotFound},
+		{fmt.Errorf("get: %w", status.Error(codes.NotFound, "no person")), exitNotFound},
+		{status.Error(codes.InvalidArgument, "bad"), exitInvalid},
+		{status.Error(codes.AlreadyExists, "taken"), exitInvalid},
+		{status.Error(codes.FailedPrecondition, "stale"), exitInvalid},
+		{status.Error(codes.OutOfRange, "too far"), exitInvalid},
+		{status.Error(codes.Unauthenticated, "no token"), exitDenied},
+		{status.Error(codes.PermissionDenied, "not allowed"), exitDenied},
+		{status.Error(codes.Unavailable, "down"), exitUnavailable},
+		{status.Error(codes.DeadlineExceeded, "slow"), exitUnavailable},
+		{status.Error(codes.Internal, "broken"), exitFailure},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestReadPersons(t *testing.T) {
+	want := []*pb.Person{{Id: 1, Name: "Juan"}, {Id: 2, Name: "Gabriel"}}
+	tests := []struct {
+		name string
+		data string
+		want []*pb.Person
+	}{
+		{"array", `[{"id": 1, "name": "Juan"}, {"id": 2, "name": "Gabriel"}]`, want},
+		{"objects", "{\"id\": 1, \"name\": \"Juan\"}\n{\"id\": 2, \"name\": \"Gabriel\"}\n", want},
+		{"indented objects", "{\n  \"id\": 1,\n  \"name\": \"Juan\"\n}{\n  \"id\": 2,\n  \"name\": \"Gabriel\"\n}", want},
+		{"one object", `{"id": 1, "name": "Juan"}`, want[:1]},
+		{"empty array", ` [] `, []*pb.Person{}},
+		{"empty", "", []*pb.Person{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "persons.json")
+			if err := os.WriteFile(file, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readPersons(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("read %d persons, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !proto.Equal(got[i], tt.want[i]) {
+					t.Errorf("person #%d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadPersonsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{"id": 1,`},
+		{"invalid array", `[{"id": 1}`},
+		{"unknown field", `{"id": 1, "nickname": "Juancho"}`},
+		{"invalid second object", `{"id": 1} {"id": "one"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "persons.json")
+			if err := os.WriteFile(file, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := readPersons(file)
+			if got := exitCode(err); got != exitInput {
+				t.Errorf("got error %v with exit code %d, want %d", err, got, exitInput)
+			}
+		})
+	}
+	if _, err := readPersons(filepath.Join(t.TempDir(), "missing.json")); exitCode(err) != exitInput {
+		t.Errorf("got error %v for a missing file, want an input error", err)
+	}
+}
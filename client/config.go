@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jackgris/go-grpc-communication/config"
+	"google.golang.org/grpc"
+)
+
+// envPrefix starts the variables overriding the config file.
+const envPrefix = "PERSONGUIDE_CLIENT_"
+
+// cfg is the configuration of the client, loaded by main.
+var cfg clientConfig
+
+// clientConfig is the configuration of the client, set from the config file,
+// the variables and the flags, in that order.
+type clientConfig struct {
+	TLS                bool
+	MTLS               bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	Addr               string
+	ServerHostOverride string
+	TokenFile          string
+	CertReloadInterval time.Duration
+	Timeout            time.Duration
+	TraceExporter      string
+	TraceFile          string
+	OTLPEndpoint       string
+	OTLPInsecure       bool
+	Output             string
+}
+
+// registerFlags defines a flag for every setting of c in fs, with its
+// default value.
+func (c *clientConfig) registerFlags(fs *flag.FlagSet) {
+	fs.String(config.FileFlag, "", "A YAML or TOML file with the settings, keyed by flag name, overridden by "+envPrefix+"<FLAG> variables and flags")
+	fs.BoolVar(&c.TLS, "tls", false, "Connection uses TLS if true, else plain TCP")
+	fs.BoolVar(&c.MTLS, "mtls", false, "Connection uses TLS and presents a client certificate to the server")
+	fs.StringVar(&c.CAFile, "ca_file", "", "The file containing the CA root cert file")
+	fs.StringVar(&c.CertFile, "cert_file", "", "The client cert file presented when using mTLS")
+	fs.StringVar(&c.KeyFile, "key_file", "", "The client key file used when using mTLS")
+	fs.StringVar(&c.Addr, "addr", "localhost:50051", "The server address in the format of host:port, or unix:///path for a Unix socket")
+	fs.StringVar(&c.ServerHostOverride, "server_host_override", "x.test.example.com", "The server name used to verify the hostname returned by the TLS handshake")
+	fs.StringVar(&c.TokenFile, "token_file", "", "A file with a bearer token sent on every call, requires TLS")
+	fs.DurationVar(&c.CertReloadInterval, "cert_reload_interval", time.Minute, "How often cert files are checked for changes, never if 0")
+	fs.DurationVar(&c.Timeout, "timeout", 10*time.Second, "How long a command may take, including all of its calls")
+	fs.StringVar(&c.TraceExporter, "trace_exporter", "", "Where spans are exported: otlp, file, or none if empty")
+	fs.StringVar(&c.TraceFile, "trace_file", "traces.json", "The file spans are appended to by the file exporter")
+	fs.StringVar(&c.OTLPEndpoint, "otlp_endpoint", "", "The host:port of the OTLP collector, OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317 if empty")
+	fs.BoolVar(&c.OTLPInsecure, "otlp_insecure", false, "Connect to the OTLP collector without TLS")
+	fs.StringVar(&c.Output, "output", "json", "The format of the results: table, json, yaml, csv or vcard")
+}
+
+// validate returns every inconsistent or out of range setting of c.
+func (c *clientConfig) validate() error {
+	var errs []error
+	if c.Addr == "" {
+		errs = append(errs, errors.New("addr is required"))
+	}
+	if !c.MTLS && (c.CertFile != "" || c.KeyFile != "") {
+		errs = append(errs, errors.New("cert_file and key_file are only used with mtls"))
+	}
+	if c.TokenFile != "" && !c.TLS && !c.MTLS {
+		errs = append(errs, errors.New("token_file requires tls or mtls"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+	if c.CertReloadInterval < 0 {
+		errs = append(errs, errors.New("cert_reload_interval can't be negative"))
+	}
+	switch c.TraceExporter {
+	case "", "otlp", "file":
+	default:
+		errs = append(errs, fmt.Errorf("unknown trace exporter %q, must be otlp or file", c.TraceExporter))
+	}
+	if _, err := newPrinter(c.Output, io.Discard); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// runConfig prints the settings of the client, without connecting to the
+// server.
+func runConfig(_ context.Context, _ *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("config")
+	format := fs.String("format", "yaml", "The format of the settings: yaml or toml")
+	if len(args) == 0 || args[0] != "dump" {
+		fs.Usage()
+		return &usageError{errors.New("missing dump subcommand")}
+	}
+	if err := parseFlags(fs, args[1:], 0); err != nil {
+		return err
+	}
+	if err := config.Dump(os.Stdout, flag.CommandLine, *format); err != nil {
+		return &usageError{err}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// loadClientCredentials returns client credentials that verify the server
+// certificate against the CA in caFile, and when the first of the
+// certificates expires. If certFile isn't empty, its certificate is presented
+// to the server.
+func loadClientCredentials(caFile, certFile, keyFile, serverName string) (credentials.TransportCredentials, time.Time, error) {
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	pool := x509.NewCertPool()
+	var expiry time.Time
+	for block, rest := pem.Decode(ca); block != nil; block, rest = pem.Decode(rest) {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("%s: %w", caFile, err)
+		}
+		pool.AddCert(cert)
+		expiry = earliest(expiry, cert.NotAfter)
+	}
+	if expiry.IsZero() {
+		return nil, time.Time{}, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	config := &tls.Config{
+		RootCAs:    pool,
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("%s: %w", certFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+		expiry = earliest(expiry, leaf.NotAfter)
+	}
+	return credentials.NewTLS(config), expiry, nil
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// bearerToken is a credentials.PerRPCCredentials sending a bearer token in
+// the "authorization" metadata of every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity makes sure the token is never sent in plain text.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// readToken returns the token in the given file, without surrounding spaces.
+func readToken(tokenFile string) (bearerToken, error) {
+	b, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", tokenFile)
+	}
+	return bearerToken(token), nil
+}
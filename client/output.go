@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// out prints the results of the commands, in the format of the output flag.
+var out printer
+
+// printer writes messages to the output in one of the formats. Some formats
+// need every message before writing any, so flush must be called at the end.
+type printer interface {
+	print(m proto.Message) error
+	flush() error
+}
+
+// newPrinter returns the printer of the format: table, json, yaml, csv or
+// vcard.
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "json":
+		return &jsonPrinter{w: w}, nil
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		return &yamlPrinter{enc: enc}, nil
+	case "table":
+		return &rowPrinter{write: func(header []string, rows [][]string) error {
+			tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+			for _, row := range rows {
+				fmt.Fprintln(tw, strings.Join(row, "\t"))
+			}
+			return tw.Flush()
+		}}, nil
+	case "csv":
+		return &rowPrinter{write: func(header []string, rows [][]string) error {
+			cw := csv.NewWriter(w)
+			cw.Write(header)
+			cw.WriteAll(rows)
+			return cw.Error()
+		}}, nil
+	case "vcard":
+		return &vcardPrinter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, must be one of table, json, yaml, csv or vcard", format)
+	}
+}
+
+// printMessage prints the message with the printer of the output flag.
+func printMessage(m proto.Message) error {
+	return out.print(m)
+}
+
+// jsonPrinter prints each message as a line of JSON.
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p *jsonPrinter) print(m proto.Message) error {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(p.w, "%s\n", b)
+	return err
+}
+
+func (p *jsonPrinter) flush() error { return nil }
+
+// yamlPrinter prints each message as a YAML document, with the field names
+// and values of the JSON mapping, in the same order.
+type yamlPrinter struct {
+	enc *yaml.Encoder
+}
+
+func (p *yamlPrinter) print(m proto.Message) error {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	node, err := jsonToYAML(json.NewDecoder(bytes.NewReader(b)))
+	if err != nil {
+		return err
+	}
+	return p.enc.Encode(node)
+}
+
+func (p *yamlPrinter) flush() error { return p.enc.Close() }
+
+// jsonToYAML converts the next JSON value of dec to a YAML node, keeping the
+// order of the object keys, which would be sorted going through a map.
+func jsonToYAML(dec *json.Decoder) (*yaml.Node, error) {
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if tok == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			value, err := jsonToYAML(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		// Consume the closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tok}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(tok.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: tok.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(tok)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// rowPrinter prints the messages as rows of a table, one per person or phone,
+// with a new header whenever the kind of message changes.
+type rowPrinter struct {
+	write  func(header []string, rows [][]string) error
+	header []string
+	rows   [][]string
+}
+
+func (p *rowPrinter) print(m proto.Message) error {
+	var header []string
+	var rows [][]string
+	switch m := typed(m).(type) {
+	case *pb.Person:
+		header, rows = personHeader, [][]string{personRow(m)}
+	case *pb.AddressBook:
+		header = personHeader
+		for _, person := range m.People {
+			rows = append(rows, personRow(person))
+		}
+	case *pb.PhoneNumber:
+		header, rows = []string{"number", "type"}, [][]string{{m.Number, m.Type.String()}}
+	default:
+		header, rows = messageRow(m)
+	}
+	if len(header) == 0 {
+		return nil
+	}
+	if strings.Join(header, "\x00") != strings.Join(p.header, "\x00") {
+		if err := p.flush(); err != nil {
+			return err
+		}
+		p.header = header
+	}
+	p.rows = append(p.rows, rows...)
+	return nil
+}
+
+func (p *rowPrinter) flush() error {
+	if p.header == nil {
+		return nil
+	}
+	err := p.write(p.header, p.rows)
+	p.header, p.rows = nil, nil
+	return err
+}
+
+var personHeader = []string{"id", "name", "email", "phones", "addresses", "last_updated"}
+
+func personRow(p *pb.Person) []string {
+	var phones, addresses []string
+	for _, phone := range p.Phones {
+		phones = append(phones, fmt.Sprintf("%s (%v)", phone.Number, phone.Type))
+	}
+	for _, a := range p.Addresses {
+		addresses = append(addresses, formatAddress(a))
+	}
+	var updated string
+	if p.LastUpdated != nil {
+		updated = p.LastUpdated.AsTime().Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(int(p.Id)), p.Name, p.Email,
+		strings.Join(phones, "; "), strings.Join(addresses, "; "), updated,
+	}
+}
+
+// formatAddress returns the non-empty fields of the address, separated by
+// commas, followed by its type.
+func formatAddress(a *pb.Address) string {
+	var parts []string
+	for _, s := range []string{a.Street, a.City, a.Region, a.PostalCode, a.Country} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return fmt.Sprintf("%s (%v)", strings.Join(parts, ", "), a.Type)
+}
+
+// messageRow returns a row with the fields of any other message, such as
+// the responses of invoke, using the JSON of the fields that are messages.
+func messageRow(m proto.Message) ([]string, [][]string) {
+	fields := m.ProtoReflect().Descriptor().Fields()
+	header := make([]string, fields.Len())
+	row := make([]string, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		header[i] = string(fd.Name())
+		if !m.ProtoReflect().Has(fd) {
+			continue
+		}
+		v := m.ProtoReflect().Get(fd)
+		if fd.IsList() {
+			var values []string
+			for j := 0; j < v.List().Len(); j++ {
+				values = append(values, valueText(fd, v.List().Get(j)))
+			}
+			row[i] = strings.Join(values, "; ")
+		} else {
+			row[i] = valueText(fd, v)
+		}
+	}
+	return header, [][]string{row}
+}
+
+func valueText(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch {
+	case fd.Enum() != nil:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case fd.Message() != nil && !fd.IsMap():
+		b, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	default:
+		return v.String()
+	}
+}
+
+// vcardPrinter prints persons as vCards, as defined by RFC 6350. Phone
+// numbers, which have no person, are printed as vCards with just the number.
+type vcardPrinter struct {
+	w io.Writer
+}
+
+func (p *vcardPrinter) print(m proto.Message) error {
+	switch m := typed(m).(type) {
+	case *pb.Person:
+		return writeVCard(p.w, m)
+	case *pb.AddressBook:
+		for _, person := range m.People {
+			if err := writeVCard(p.w, person); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *pb.PhoneNumber:
+		return writePhoneVCard(p.w, m)
+	default:
+		return &usageError{fmt.Errorf("%s can't be printed as a vCard", m.ProtoReflect().Descriptor().FullName())}
+	}
+}
+
+func (p *vcardPrinter) flush() error { return nil }
+
+var vcardPhoneTypes = map[pb.PhoneType]string{
+	pb.PhoneType_MOBILE: "cell",
+	pb.PhoneType_HOME:   "home",
+	pb.PhoneType_WORK:   "work",
+}
+
+func writeVCard(w io.Writer, p *pb.Person) error {
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"FN:" + vcardEscape(p.Name),
+		"N:" + vcardEscape(p.Name) + ";;;;",
+		fmt.Sprintf("UID:urn:personguide:person:%d", p.Id),
+	}
+	if p.Email != "" {
+		lines = append(lines, "EMAIL:"+vcardEscape(p.Email))
+	}
+	for _, phone := range p.Phones {
+		lines = append(lines, vcardTel(phone))
+	}
+	for _, a := range p.Addresses {
+		adr := "ADR"
+		if a.Type != pb.Address_OTHER {
+			adr += ";TYPE=" + strings.ToLower(a.Type.String())
+		}
+		// Post office box and extended address, which addresses don't have,
+		// come before the street.
+		lines = append(lines, fmt.Sprintf("%s:;;%s;%s;%s;%s;%s", adr, vcardEscape(a.Street), vcardEscape(a.City),
+			vcardEscape(a.Region), vcardEscape(a.PostalCode), vcardEscape(a.Country)))
+	}
+	if p.LastUpdated != nil {
+		lines = append(lines, "REV:"+p.LastUpdated.AsTime().UTC().Format("20060102T150405Z"))
+	}
+	lines = append(lines, "END:VCARD")
+	return writeVCardLines(w, lines)
+}
+
+// writePhoneVCard writes a vCard with the phone number alone, also used as
+// the formatted name required by every vCard.
+func writePhoneVCard(w io.Writer, phone *pb.PhoneNumber) error {
+	return writeVCardLines(w, []string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"FN:" + vcardEscape(phone.Number),
+		vcardTel(phone),
+		"END:VCARD",
+	})
+}
+
+func vcardTel(phone *pb.PhoneNumber) string {
+	return fmt.Sprintf("TEL;VALUE=text;TYPE=%s:%s", vcardPhoneTypes[phone.Type], vcardEscape(phone.Number))
+}
+
+func writeVCardLines(w io.Writer, lines []string) error {
+	_, err := io.WriteString(w, strings.Join(lines, "\r\n")+"\r\n")
+	return err
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+
+func vcardEscape(s string) string {
+	return vcardEscaper.Replace(s)
+}
+
+// typed returns the generated message for the messages of the person guide
+// built from descriptors, like the responses of invoke, so they are printed
+// the same way.
+func typed(m proto.Message) proto.Message {
+	var t proto.Message
+	switch m.ProtoReflect().Descriptor().FullName() {
+	case "personguide.Person":
+		t = &pb.Person{}
+	case "personguide.AddressBook":
+		t = &pb.AddressBook{}
+	case "personguide.PhoneNumber":
+		t = &pb.PhoneNumber{}
+	default:
+		return m
+	}
+	if m.ProtoReflect().Type() == t.ProtoReflect().Type() {
+		return m
+	}
+	b, err := proto.Marshal(m)
+	if err != nil || proto.Unmarshal(b, t) != nil {
+		return m
+	}
+	return t
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// outputMessages are printed in every format. The kind of message changes
+// twice, so the table and csv printers write three headers.
+var outputMessages = []proto.Message{
+	&pb.Person{
+		Id:    1,
+		Name:  "Pérez, Juan; Jr.",
+		Email: "juan@gmail.com",
+		Phones: []*pb.PhoneNumber{
+			{Number: "+54 11 1234-5678", Type: pb.PhoneType_MOBILE},
+			{Number: "4321", Type: pb.PhoneType_WORK},
+		},
+		Addresses: []*pb.Address{{
+			Street:     "Av. Corrientes 1234, Piso 5",
+			City:       "Buenos Aires",
+			PostalCode: "C1043",
+			Country:    "Argentina",
+			Type:       pb.Address_HOME,
+		}},
+		LastUpdated: timestamppb.New(time.Date(2023, 10, 1, 12, 30, 0, 0, time.UTC)),
+	},
+	&pb.PhoneNumber{Number: "1234", Type: pb.PhoneType_HOME},
+	&pb.PhoneNumber{Number: `back\slash`, Type: pb.PhoneType_MOBILE},
+	&pb.AddressBook{People: []*pb.Person{
+		{Id: 2, Name: "Gabriel"},
+		{Id: 3, Name: "Albert", Email: "albert@gmail.com"},
+	}},
+}
+
+func TestPrinters(t *testing.T) {
+	for _, format := range []string{"table", "csv", "yaml", "vcard"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			p, err := newPrinter(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range outputMessages {
+				if err := p.print(m); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := p.flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "output."+format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("printed:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestVCardRejectsOtherMessages(t *testing.T) {
+	p, err := newPrinter("vcard", &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.print(&pb.Adress{City: "Buenos Aires"}); exitCode(err) != exitUsage {
+		t.Errorf("got %v, want a usage error", err)
+	}
+}
+
+func TestVCardEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Juan", "Juan"},
+		{"Pérez, Juan", `Pérez\, Juan`},
+		{"a;b", `a\;b`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", `two\nlines`},
+	}
+	for _, tt := range tests {
+		if got := vcardEscape(tt.in); got != tt.want {
+			t.Errorf("vcardEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewPrinterUnknownFormat(t *testing.T) {
+	if _, err := newPrinter("xml", &bytes.Buffer{}); err == nil {
+		t.Error("newPrinter succeeded for an unknown format")
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// reflectionClient finds the services of the server and their messages with
+// the server reflection service, so they can be called without generated
+// code.
+type reflectionClient struct {
+	stream rpb.ServerReflection_ServerReflectionInfoClient
+	protos map[string]*descriptorpb.FileDescriptorProto // by file name
+}
+
+func newReflectionClient(ctx context.Context, conn *grpc.ClientConn) (*reflectionClient, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &reflectionClient{stream: stream, protos: make(map[string]*descriptorpb.FileDescriptorProto)}, nil
+}
+
+// close ends the stream, waiting for the server to finish it so the call
+// isn't cancelled.
+func (c *reflectionClient) close() {
+	if err := c.stream.CloseSend(); err != nil {
+		return
+	}
+	for {
+		if _, err := c.stream.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *reflectionClient) request(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	if err := c.stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := c.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, fmt.Errorf("reflection error %d: %s", e.ErrorCode, e.ErrorMessage)
+	}
+	return resp, nil
+}
+
+// listServices returns the names of the services of the server, sorted.
+func (c *reflectionClient) listServices() ([]string, error) {
+	resp, err := c.request(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// resolve returns the descriptor of the fully qualified symbol, a service,
+// method, message or enum.
+func (c *reflectionClient) resolve(symbol string) (protoreflect.Descriptor, error) {
+	resp, err := c.request(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.addFiles(resp); err != nil {
+		return nil, err
+	}
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	return files.FindDescriptorByName(protoreflect.FullName(symbol))
+}
+
+// addFiles keeps the files of the response, asking for their dependencies
+// that the server didn't send.
+func (c *reflectionClient) addFiles(resp *rpb.ServerReflectionResponse) error {
+	for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fd := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fd); err != nil {
+			return err
+		}
+		c.protos[fd.GetName()] = fd
+	}
+	for _, fd := range c.protos {
+		for _, dep := range fd.Dependency {
+			if _, ok := c.protos[dep]; ok {
+				continue
+			}
+			resp, err := c.request(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return err
+			}
+			// Start again, as the map changed while ranging over it.
+			return c.addFiles(resp)
+		}
+	}
+	return nil
+}
+
+func (c *reflectionClient) files() (*protoregistry.Files, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range c.protos {
+		set.File = append(set.File, fd)
+	}
+	return protodesc.NewFiles(set)
+}
+
+func runServices(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	if err := parseFlags(newFlagSet("services"), args, 0); err != nil {
+		return err
+	}
+	rc, err := newReflectionClient(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("reflection failed: %w", err)
+	}
+	defer rc.close()
+	names, err := rc.listServices()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func runDescribe(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("describe")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return &usageError{errors.New("the symbol to describe is required")}
+	}
+	rc, err := newReflectionClient(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("reflection failed: %w", err)
+	}
+	defer rc.close()
+	d, err := rc.resolve(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("describe %s failed: %w", fs.Arg(0), err)
+	}
+	fmt.Print(describe(d))
+	return nil
+}
+
+func runInvoke(ctx context.Context, conn *grpc.ClientConn, args []string) error {
+	fs := newFlagSet("invoke")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return &usageError{errors.New("the method to invoke is required")}
+	}
+	body := "-"
+	if fs.NArg() == 2 {
+		body = fs.Arg(1)
+	}
+	rc, err := newReflectionClient(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("reflection failed: %w", err)
+	}
+	defer rc.close()
+	return invoke(ctx, conn, rc, fs.Arg(0), body)
+}
+
+// describe returns a proto like definition of the descriptor.
+func describe(d protoreflect.Descriptor) string {
+	var b strings.Builder
+	switch d := d.(type) {
+	case protoreflect.ServiceDescriptor:
+		fmt.Fprintf(&b, "service %s {\n", d.FullName())
+		for i := 0; i < d.Methods().Len(); i++ {
+			fmt.Fprintf(&b, "  %s\n", methodSignature(d.Methods().Get(i)))
+		}
+		b.WriteString("}\n")
+	case protoreflect.MethodDescriptor:
+		fmt.Fprintf(&b, "%s\n", methodSignature(d))
+	case protoreflect.MessageDescriptor:
+		fmt.Fprintf(&b, "message %s {\n", d.FullName())
+		for i := 0; i < d.Fields().Len(); i++ {
+			f := d.Fields().Get(i)
+			label := ""
+			if f.IsList() {
+				label = "repeated "
+			} else if f.HasOptionalKeyword() {
+				label = "optional "
+			}
+			fmt.Fprintf(&b, "  %s%s %s = %d;\n", label, fieldType(f), f.Name(), f.Number())
+		}
+		b.WriteString("}\n")
+	case protoreflect.EnumDescriptor:
+		fmt.Fprintf(&b, "enum %s {\n", d.FullName())
+		for i := 0; i < d.Values().Len(); i++ {
+			v := d.Values().Get(i)
+			fmt.Fprintf(&b, "  %s = %d;\n", v.Name(), v.Number())
+		}
+		b.WriteString("}\n")
+	default:
+		fmt.Fprintf(&b, "%s\n", d.FullName())
+	}
+	return b.String()
+}
+
+func methodSignature(m protoreflect.MethodDescriptor) string {
+	in, out := string(m.Input().FullName()), string(m.Output().FullName())
+	if m.IsStreamingClient() {
+		in = "stream " + in
+	}
+	if m.IsStreamingServer() {
+		out = "stream " + out
+	}
+	return fmt.Sprintf("rpc %s(%s) returns (%s);", m.Name(), in, out)
+}
+
+func fieldType(f protoreflect.FieldDescriptor) string {
+	switch {
+	case f.IsMap():
+		return fmt.Sprintf("map<%s, %s>", fieldType(f.MapKey()), fieldType(f.MapValue()))
+	case f.Message() != nil:
+		return string(f.Message().FullName())
+	case f.Enum() != nil:
+		return string(f.Enum().FullName())
+	default:
+		return f.Kind().String()
+	}
+}
+
+// invoke calls the method with the requests in body, a JSON string or "-" to
+// read it from stdin, printing each response as a line of JSON. Client
+// streaming methods get one request per JSON value in body, none if empty.
+func invoke(ctx context.Context, conn *grpc.ClientConn, rc *reflectionClient, method, body string) error {
+	name := strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1)
+	d, err := rc.resolve(name)
+	if err != nil {
+		return err
+	}
+	md, ok := d.(protoreflect.MethodDescriptor)
+	if !ok {
+		return fmt.Errorf("%s isn't a method", name)
+	}
+
+	var r io.Reader = strings.NewReader(body)
+	if body == "-" {
+		r = os.Stdin
+	}
+	var requests []proto.Message
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return &inputError{fmt.Errorf("reading requests: %w", err)}
+		}
+		req := dynamicpb.NewMessage(md.Input())
+		if err := protojson.Unmarshal(raw, req); err != nil {
+			return &inputError{fmt.Errorf("request #%d: %w", len(requests), err)}
+		}
+		requests = append(requests, req)
+	}
+	if len(requests) == 0 && !md.IsStreamingClient() {
+		// Calling a method without arguments, such as with an empty message.
+		// Client streaming methods are just called without requests.
+		requests = append(requests, dynamicpb.NewMessage(md.Input()))
+	}
+	if !md.IsStreamingClient() && len(requests) > 1 {
+		return &usageError{errors.New("only client streaming methods accept more than one request")}
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		ServerStreams: md.IsStreamingServer(),
+		ClientStreams: md.IsStreamingClient(),
+	}, fullMethod)
+	if err != nil {
+		return err
+	}
+	for _, req := range requests {
+		if err := stream.SendMsg(req); err != nil {
+			// The reason of the failure is returned by RecvMsg.
+			break
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		resp := dynamicpb.NewMessage(md.Output())
+		if err := stream.RecvMsg(resp); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := printMessage(resp); err != nil {
+			return err
+		}
+	}
+}
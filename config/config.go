@@ -0,0 +1,161 @@
+// Package config loads the settings of the person guide client and server
+// from a YAML or TOML file, environment variables and command line flags,
+// each one overriding the previous.
+//
+// The settings are the flags of the binaries: the keys of the file are the
+// flag names, and the variables are the flag names in upper case with a
+// prefix, like PERSONGUIDE_SERVER_PORT for the port flag of the server.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileFlag is the name of the flag with the path of the config file.
+const FileFlag = "config"
+
+// EnvName returns the variable setting the flag with the given name.
+func EnvName(prefix, name string) string {
+	return prefix + strings.ToUpper(name)
+}
+
+// Load parses args with fs, whose flags are first set from the config file,
+// if any, and then from the variables starting with prefix. The path of the
+// file is given by the config flag of fs, or by its variable.
+func Load(fs *flag.FlagSet, args []string, prefix string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	explicit := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+
+	file, ok := explicit[FileFlag]
+	if !ok {
+		file = os.Getenv(EnvName(prefix, FileFlag))
+	}
+	if file != "" {
+		if err := loadFile(fs, file); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := EnvName(prefix, f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok || f.Name == FileFlag || err != nil {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", name, setErr)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// The flags win over the file and the variables.
+	for name, value := range explicit {
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFile sets the flags of fs from the settings in file, which is YAML or
+// TOML depending on its extension.
+func loadFile(fs *flag.FlagSet, file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	settings := map[string]interface{}{}
+	switch ext := filepath.Ext(file); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &settings)
+	case ".toml":
+		err = toml.Unmarshal(b, &settings)
+	default:
+		return fmt.Errorf("%s: unknown config format %q, must be .yaml, .yml or .toml", file, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	for key, value := range settings {
+		if key == FileFlag || fs.Lookup(key) == nil {
+			return fmt.Errorf("%s: unknown setting %q", file, key)
+		}
+		switch value.(type) {
+		case string, bool, int, int64, float64:
+		default:
+			return fmt.Errorf("%s: %s must be a string, number or boolean", file, key)
+		}
+		// A number given to a string flag, like the octal 0660, was already
+		// converted by the decoder, and printing it back loses how it was
+		// written.
+		if _, ok := value.(string); !ok && isString(fs.Lookup(key)) {
+			return fmt.Errorf("%s: %s must be a quoted string", file, key)
+		}
+		if err := fs.Set(key, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("%s: %s: %w", file, key, err)
+		}
+	}
+	return nil
+}
+
+// isString reports whether the value of f is a string.
+func isString(f *flag.Flag) bool {
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	_, ok = g.Get().(string)
+	return ok
+}
+
+// Dump writes the value of every flag of fs but the config one to w, in the
+// yaml or toml format, so it can be loaded back.
+func Dump(w io.Writer, fs *flag.FlagSet, format string) error {
+	// Both encoders sort the keys, like the flags are listed.
+	values := map[string]interface{}{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == FileFlag {
+			return
+		}
+		var value interface{} = f.Value.String()
+		if g, ok := f.Value.(flag.Getter); ok {
+			value = g.Get()
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		values[f.Name] = value
+	})
+
+	switch format {
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(values); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "toml":
+		return toml.NewEncoder(w).Encode(values)
+	default:
+		return fmt.Errorf("unknown config format %q, must be yaml or toml", format)
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testSettings struct {
+	port    int
+	name    string
+	mode    string
+	timeout time.Duration
+}
+
+func newTestFlagSet(s *testSettings) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String(FileFlag, "", "")
+	fs.IntVar(&s.port, "port", 50051, "")
+	fs.StringVar(&s.name, "name", "default", "")
+	fs.StringVar(&s.mode, "mode", "0600", "")
+	fs.DurationVar(&s.timeout, "timeout", time.Second, "")
+	return fs
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", "port: 1\nname: file\nmode: \"0660\"\ntimeout: 5s\n")
+	tomlFile := writeFile(t, "config.toml", "port = 1\nname = \"file\"\nmode = \"0660\"\ntimeout = \"5s\"\n")
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want testSettings
+	}{
+		{
+			name: "defaults",
+			want: testSettings{port: 50051, name: "default", mode: "0600", timeout: time.Second},
+		},
+		{
+			name: "yaml file",
+			args: []string{"-config", yamlFile},
+			want: testSettings{port: 1, name: "file", mode: "0660", timeout: 5 * time.Second},
+		},
+		{
+			name: "toml file",
+			args: []string{"-config", tomlFile},
+			want: testSettings{port: 1, name: "file", mode: "0660", timeout: 5 * time.Second},
+		},
+		{
+			name: "file from a variable",
+			env:  map[string]string{"TEST_CONFIG": yamlFile},
+			want: testSettings{port: 1, name: "file", mode: "0660", timeout: 5 * time.Second},
+		},
+		{
+			name: "variables over file",
+			env:  map[string]string{"TEST_PORT": "2", "TEST_TIMEOUT": "1m"},
+			args: []string{"-config", yamlFile},
+			want: testSettings{port: 2, name: "file", mode: "0660", timeout: time.Minute},
+		},
+		{
+			name: "flags over variables and file",
+			env:  map[string]string{"TEST_PORT": "2", "TEST_NAME": "env"},
+			args: []string{"-config", yamlFile, "-port", "3"},
+			want: testSettings{port: 3, name: "env", mode: "0660", timeout: 5 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			var got testSettings
+			if err := Load(newTestFlagSet(&got), tt.args, "TEST_"); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+	}{
+		{name: "unknown setting", file: "config.yaml", content: "prot: 1\n"},
+		{name: "unquoted number for a string", file: "config.yaml", content: "mode: 0660\n"},
+		{name: "list value", file: "config.yaml", content: "name: [a, b]\n"},
+		{name: "invalid value", file: "config.toml", content: "port = \"many\"\n"},
+		{name: "unknown format", file: "config.json", content: "{}"},
+		{name: "invalid variable", file: "config.yaml", content: "port: 1\n", env: map[string]string{"TEST_PORT": "many"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			var s testSettings
+			args := []string{"-config", writeFile(t, tt.file, tt.content)}
+			if err := Load(newTestFlagSet(&s), args, "TEST_"); err == nil {
+				t.Error("Load succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package guide
+
+import (
+	"strings"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// matchesAdress reports whether any address of the person matches the query.
+func matchesAdress(person *pb.Person, query *pb.Adress) bool {
+	if isEmptyAdress(query) {
+		return true
+	}
+	for _, a := range person.Addresses {
+		if matchesAddress(a, query) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyAdress(query *pb.Adress) bool {
+	return query.GetCity() == "" && query.GetPostalCode() == "" &&
+		query.GetRegion() == "" && query.GetCountry() == ""
+}
+
+// matchesAddress reports whether the address matches all the non-empty
+// fields of the query.
+func matchesAddress(a *pb.Address, query *pb.Adress) bool {
+	if query.City != "" && !matchField(a.City, query.City, query.Prefix) {
+		return false
+	}
+	if query.PostalCode != "" &&
+		!matchField(normalizePostalCode(a.PostalCode), normalizePostalCode(query.PostalCode), query.Prefix) {
+		return false
+	}
+	if query.Region != "" && !strings.EqualFold(a.Region, query.Region) {
+		return false
+	}
+	if query.Country != "" && !strings.EqualFold(a.Country, query.Country) {
+		return false
+	}
+	return true
+}
+
+// matchField compares value and want ignoring case, checking only that want
+// is a prefix of value if prefix is true.
+func matchField(value, want string, prefix bool) bool {
+	if prefix {
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(want))
+	}
+	return strings.EqualFold(value, want)
+}
+
+// normalizePostalCode removes the spaces of a postal code, so "SW1A 1AA"
+// and "sw1a1aa" are the same code.
+func normalizePostalCode(code string) string {
+	return strings.Join(strings.Fields(code), "")
+}
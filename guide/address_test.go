@@ -0,0 +1,45 @@
+package guide
+
+import (
+	"testing"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+func TestMatchesAdress(t *testing.T) {
+	person := &pb.Person{Addresses: []*pb.Address{
+		{City: "Buenos Aires", PostalCode: "C1043", Country: "AR"},
+		{City: "London", PostalCode: "SW1A 2AA", Region: "England", Country: "GB"},
+	}}
+	tests := []struct {
+		name  string
+		query *pb.Adress
+		want  bool
+	}{
+		{"nil query", nil, true},
+		{"empty query", &pb.Adress{Name: "everyone"}, true},
+		{"city ignoring case", &pb.Adress{City: "buenos aires"}, true},
+		{"other city", &pb.Adress{City: "Córdoba"}, false},
+		{"partial city", &pb.Adress{City: "Buenos"}, false},
+		{"city prefix", &pb.Adress{City: "Buenos", Prefix: true}, true},
+		{"postal code without spaces", &pb.Adress{PostalCode: "sw1a2aa"}, true},
+		{"postal code prefix", &pb.Adress{PostalCode: "SW1A", Prefix: true}, true},
+		{"region", &pb.Adress{Region: "england"}, true},
+		{"country", &pb.Adress{Country: "gb"}, true},
+		{"all fields of one address", &pb.Adress{City: "London", Country: "GB"}, true},
+		{"fields of different addresses", &pb.Adress{City: "London", Country: "AR"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAdress(person, tt.query); got != tt.want {
+				t.Errorf("matchesAdress(%v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesAdressWithoutAddresses(t *testing.T) {
+	if matchesAdress(&pb.Person{}, &pb.Adress{City: "London"}) {
+		t.Error("a person without addresses matched a city")
+	}
+}
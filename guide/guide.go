@@ -0,0 +1,184 @@
+// Package guide implements the person guide service, whose definition can be
+// found in personguide/person_guide.proto, so it can be served by any gRPC
+// server:
+//
+//	s := guide.New(guide.WithStore(store), guide.WithLogger(logger))
+//	s.Register(grpcServer)
+//	if err := s.Load(""); err != nil {
+//		...
+//	}
+//
+// Calls are rejected with Unavailable until Load returns, and are checked by
+// the Authorizer given with WithAuth, if any, like the ones of the auth
+// package, whose caller is then read with auth.IdentityFromContext.
+package guide
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// Authorizer decides whether a call to fullMethod, like
+// "/personguide.PersonGuide/GetPerson", may go on, returning a status error
+// if it may not. The returned context is the one passed to the handler, so
+// it can carry the identity of the caller.
+type Authorizer func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// Option configures a PersonGuideServer.
+type Option func(*PersonGuideServer)
+
+// WithStore keeps the persons in store, instead of in memory.
+func WithStore(store Store) Option {
+	return func(s *PersonGuideServer) { s.raw = store }
+}
+
+// WithLogger logs with logger, instead of slog.Default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *PersonGuideServer) { s.logger = logger }
+}
+
+// WithClock stamps the persons saved with the time returned by now, instead
+// of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(s *PersonGuideServer) { s.now = now }
+}
+
+// WithAuth checks every call with auth before handling it. It only applies
+// to the service registered with Register.
+func WithAuth(auth Authorizer) Option {
+	return func(s *PersonGuideServer) { s.auth = auth }
+}
+
+// New returns a server with the given options.
+func New(opts ...Option) *PersonGuideServer {
+	s := &PersonGuideServer{
+		raw:    NewMemoryStore(),
+		logger: slog.Default(),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Load indexes the persons in the store and loads the persons and address
+// books in filePath, if not empty, replacing the ones with the same id or
+// name. The file is either a JSON array of persons, or an object with a
+// "persons" array and an optional "address_books" object mapping a book name
+// to a list of address books. When no address books are given, all persons
+// are put in a single book named "book".
+func (s *PersonGuideServer) Load(filePath string) error {
+	indexed, err := newIndexedStore(s.raw)
+	if err != nil {
+		return fmt.Errorf("indexing persons: %w", err)
+	}
+	s.store = indexed
+	if filePath != "" {
+		if err := s.loadFeatures(filePath); err != nil {
+			return fmt.Errorf("loading persons: %w", err)
+		}
+	}
+	s.loaded.Store(true)
+	return nil
+}
+
+// CountPersons returns the number of persons stored, 0 until Load returns.
+func (s *PersonGuideServer) CountPersons() int {
+	if !s.loaded.Load() {
+		return 0
+	}
+	return s.store.CountPersons()
+}
+
+// CountAddressBooks returns the number of address books stored under all
+// names, 0 until Load returns.
+func (s *PersonGuideServer) CountAddressBooks() int {
+	if !s.loaded.Load() {
+		return 0
+	}
+	return s.store.CountAddressBooks()
+}
+
+// Register registers the service on r, rejecting the calls denied by the
+// Authorizer. The check runs after the interceptors of the server, so they
+// see the rejected calls too.
+func (s *PersonGuideServer) Register(r grpc.ServiceRegistrar) {
+	desc := pb.PersonGuide_ServiceDesc
+	desc.Methods = append([]grpc.MethodDesc(nil), desc.Methods...)
+	for i := range desc.Methods {
+		handler := desc.Methods[i].Handler
+		desc.Methods[i].Handler = func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			return handler(srv, ctx, dec, s.checkUnary(interceptor))
+		}
+	}
+	desc.Streams = append([]grpc.StreamDesc(nil), desc.Streams...)
+	for i := range desc.Streams {
+		handler := desc.Streams[i].Handler
+		fullMethod := "/" + desc.ServiceName + "/" + desc.Streams[i].StreamName
+		desc.Streams[i].Handler = func(srv interface{}, stream grpc.ServerStream) error {
+			ctx, err := s.authorize(stream.Context(), fullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+		}
+	}
+	r.RegisterService(&desc, s)
+}
+
+// checkUnary returns an interceptor running interceptor, the ones of the
+// server if any, and then authorize.
+func (s *PersonGuideServer) checkUnary(interceptor grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	check := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := s.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	if interceptor == nil {
+		return check
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return check(ctx, req, info, handler)
+		})
+	}
+}
+
+// authorize checks that a call to fullMethod is allowed.
+func (s *PersonGuideServer) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if s.auth == nil {
+		return ctx, nil
+	}
+	return s.auth(ctx, fullMethod)
+}
+
+// checkLoaded returns an Unavailable error until Load returns, so the
+// handlers don't use the store before it's indexed.
+func (s *PersonGuideServer) checkLoaded() error {
+	if !s.loaded.Load() {
+		return status.Error(codes.Unavailable, "the persons aren't loaded yet, try again later")
+	}
+	return nil
+}
+
+// authorizedStream is a stream whose context is the one returned by the
+// Authorizer.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
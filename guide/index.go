@@ -0,0 +1,242 @@
+package guide
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// indexedStore is a Store indexing the persons of the wrapped store by email
+// and phone number, so looking them up doesn't need to scan the store. Only
+// the ids and the indexed keys are kept in memory, the persons are read from
+// the wrapped store. The indexes are updated on every write. It also counts
+// the address books saved under every name.
+type indexedStore struct {
+	Store
+
+	mu      sync.RWMutex
+	byID    map[int32]indexKeys
+	byEmail map[string]map[int32]bool
+	byPhone map[string]map[int32]bool
+	books   map[string]int
+	maxID   int32 // largest id ever stored, even if deleted since
+}
+
+// indexKeys are the keys a person is indexed by, to remove them when it
+// changes.
+type indexKeys struct {
+	email  string
+	phones []string
+}
+
+// newIndexedStore builds the indexes of all the persons in store.
+func newIndexedStore(store Store) (*indexedStore, error) {
+	maxID, err := store.MaxPersonID()
+	if err != nil {
+		return nil, err
+	}
+	s := &indexedStore{
+		Store:   store,
+		byID:    make(map[int32]indexKeys),
+		byEmail: make(map[string]map[int32]bool),
+		byPhone: make(map[string]map[int32]bool),
+		books:   make(map[string]int),
+		maxID:   maxID,
+	}
+	err = store.ScanPersons(func(p *pb.Person) bool {
+		s.add(p)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = store.ScanAddressBooks(func(name string, books []*pb.AddressBook) bool {
+		s.books[name] = len(books)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *indexedStore) PutPerson(person *pb.Person) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Store.PutPerson(person); err != nil {
+		return err
+	}
+	s.remove(person.Id)
+	s.add(person)
+	return nil
+}
+
+func (s *indexedStore) DeletePerson(id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Store.DeletePerson(id); err != nil {
+		return err
+	}
+	s.remove(id)
+	return nil
+}
+
+func (s *indexedStore) PutAddressBooks(name string, books []*pb.AddressBook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Store.PutAddressBooks(name, books); err != nil {
+		return err
+	}
+	s.books[name] = len(books)
+	return nil
+}
+
+func (s *indexedStore) DeleteAddressBooks(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Store.DeleteAddressBooks(name); err != nil {
+		return err
+	}
+	delete(s.books, name)
+	return nil
+}
+
+// PersonsByEmail returns the persons with the given email, ignoring case,
+// ordered by id.
+func (s *indexedStore) PersonsByEmail(email string) ([]*pb.Person, error) {
+	s.mu.RLock()
+	ids := sortedIDs(s.byEmail[normalizeEmail(email)])
+	s.mu.RUnlock()
+	return s.lookup(ids)
+}
+
+// PersonsByPhone returns the persons having the given phone number, compared
+// after normalizing it, ordered by id.
+func (s *indexedStore) PersonsByPhone(number string) ([]*pb.Person, error) {
+	s.mu.RLock()
+	ids := sortedIDs(s.byPhone[normalizePhone(number)])
+	s.mu.RUnlock()
+	return s.lookup(ids)
+}
+
+// CountPersons returns the number of persons in the store.
+func (s *indexedStore) CountPersons() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byID)
+}
+
+// CountAddressBooks returns the number of address books in the store, under
+// all names.
+func (s *indexedStore) CountAddressBooks() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var n int
+	for _, count := range s.books {
+		n += count
+	}
+	return n
+}
+
+// MaxPersonID returns the largest id of the persons ever stored, including
+// the ones deleted since, or 0 if none.
+func (s *indexedStore) MaxPersonID() (int32, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxID, nil
+}
+
+func sortedIDs(set map[int32]bool) []int32 {
+	ids := make([]int32, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// lookup reads the persons with the given ids from the wrapped store,
+// skipping the ones deleted since they were looked up.
+func (s *indexedStore) lookup(ids []int32) ([]*pb.Person, error) {
+	persons := make([]*pb.Person, 0, len(ids))
+	for _, id := range ids {
+		p, err := s.Store.GetPerson(id)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		persons = append(persons, p)
+	}
+	return persons, nil
+}
+
+// add indexes the person. The caller must hold s.mu.
+func (s *indexedStore) add(p *pb.Person) {
+	keys := indexKeys{email: normalizeEmail(p.Email)}
+	if keys.email != "" {
+		addKey(s.byEmail, keys.email, p.Id)
+	}
+	for _, phone := range p.Phones {
+		if number := normalizePhone(phone.Number); number != "" {
+			addKey(s.byPhone, number, p.Id)
+			keys.phones = append(keys.phones, number)
+		}
+	}
+	s.byID[p.Id] = keys
+	if p.Id > s.maxID {
+		s.maxID = p.Id
+	}
+}
+
+// remove drops the person with the given id from the indexes. The caller
+// must hold s.mu.
+func (s *indexedStore) remove(id int32) {
+	keys, ok := s.byID[id]
+	if !ok {
+		return
+	}
+	delete(s.byID, id)
+	removeKey(s.byEmail, keys.email, id)
+	for _, number := range keys.phones {
+		removeKey(s.byPhone, number, id)
+	}
+}
+
+func addKey(index map[string]map[int32]bool, key string, id int32) {
+	ids, ok := index[key]
+	if !ok {
+		ids = make(map[int32]bool)
+		index[key] = ids
+	}
+	ids[id] = true
+}
+
+func removeKey(index map[string]map[int32]bool, key string, id int32) {
+	ids, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(index, key)
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizePhone keeps only the digits of a phone number, so "+54 (11) 1234-5678"
+// and "541112345678" are the same number.
+func normalizePhone(number string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+}
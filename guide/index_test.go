@@ -0,0 +1,139 @@
+package guide
+
+import (
+	"testing"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+func TestIndexedStore(t *testing.T) {
+	raw := NewMemoryStore()
+	// Persons already in the wrapped store are indexed too.
+	if err := raw.PutPerson(&pb.Person{Id: 3, Name: "Ana", Email: "Ana@Example.com", Phones: []*pb.PhoneNumber{{Number: "+54 (11) 1234"}}}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newIndexedStore(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name    string
+		do      func() error
+		email   string
+		byEmail []int32
+		phone   string
+		byPhone []int32
+		count   int
+		maxID   int32
+	}{
+		{
+			name:    "indexed on creation",
+			do:      func() error { return nil },
+			email:   " ana@example.COM",
+			byEmail: []int32{3},
+			phone:   "54111234",
+			byPhone: []int32{3},
+			count:   1,
+			maxID:   3,
+		},
+		{
+			name: "new person with the same email",
+			do: func() error {
+				return s.PutPerson(&pb.Person{Id: 1, Name: "Ana B", Email: "ana@example.com", Phones: []*pb.PhoneNumber{{Number: "555"}}})
+			},
+			email:   "ana@example.com",
+			byEmail: []int32{1, 3},
+			phone:   "555",
+			byPhone: []int32{1},
+			count:   2,
+			maxID:   3,
+		},
+		{
+			name: "changed email and phone",
+			do: func() error {
+				return s.PutPerson(&pb.Person{Id: 3, Name: "Ana", Email: "ana@other.com", Phones: []*pb.PhoneNumber{{Number: "555"}}})
+			},
+			email:   "ana@example.com",
+			byEmail: []int32{1},
+			phone:   "555",
+			byPhone: []int32{1, 3},
+			count:   2,
+			maxID:   3,
+		},
+		{
+			name:    "old phone removed",
+			do:      func() error { return nil },
+			email:   "ana@other.com",
+			byEmail: []int32{3},
+			phone:   "54111234",
+			byPhone: []int32{},
+			count:   2,
+			maxID:   3,
+		},
+		{
+			name:    "deleted person keeps the max id",
+			do:      func() error { return s.DeletePerson(3) },
+			email:   "ana@other.com",
+			byEmail: []int32{},
+			phone:   "555",
+			byPhone: []int32{1},
+			count:   1,
+			maxID:   3,
+		},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		byEmail, err := s.PersonsByEmail(step.email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := personIDs(byEmail); !equalIDs(got, step.byEmail) {
+			t.Errorf("%s: PersonsByEmail(%q) = %v, want %v", step.name, step.email, got, step.byEmail)
+		}
+		byPhone, err := s.PersonsByPhone(step.phone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := personIDs(byPhone); !equalIDs(got, step.byPhone) {
+			t.Errorf("%s: PersonsByPhone(%q) = %v, want %v", step.name, step.phone, got, step.byPhone)
+		}
+		if got := s.CountPersons(); got != step.count {
+			t.Errorf("%s: CountPersons() = %d, want %d", step.name, got, step.count)
+		}
+		if got, _ := s.MaxPersonID(); got != step.maxID {
+			t.Errorf("%s: MaxPersonID() = %d, want %d", step.name, got, step.maxID)
+		}
+	}
+
+	if err := s.DeletePerson(3); err != ErrNotFound {
+		t.Errorf("deleting a deleted person: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestIndexedStoreCountsAddressBooks(t *testing.T) {
+	s, err := newIndexedStore(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutAddressBooks("a", []*pb.AddressBook{{}, {}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutAddressBooks("b", []*pb.AddressBook{{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutAddressBooks("a", []*pb.AddressBook{{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.CountAddressBooks(); got != 2 {
+		t.Errorf("CountAddressBooks() = %d, want 2", got)
+	}
+	if err := s.DeleteAddressBooks("b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.CountAddressBooks(); got != 1 {
+		t.Errorf("CountAddressBooks() after delete = %d, want 1", got)
+	}
+}
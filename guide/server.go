@@ -0,0 +1,472 @@
+package guide
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// PersonGuideServer implements the PersonGuide service on a Store. It's
+// created by New, and can't be used until Load returns.
+type PersonGuideServer struct {
+	pb.UnimplementedPersonGuideServer
+	raw    Store
+	logger *slog.Logger
+	now    func() time.Time
+	auth   Authorizer
+
+	store  *indexedStore
+	loaded atomic.Bool
+
+	mu sync.Mutex // serializes read-modify-write of the store
+}
+
+// GetPhone returns the phone of the requested type at the given person.
+func (s *PersonGuideServer) GetPhone(ctx context.Context, req *pb.GetPhoneRequest) (*pb.PhoneNumber, error) {
+	if err := s.checkLoaded(); err != nil {
+		return nil, err
+	}
+	var person *pb.Person
+	if req.Id != 0 || req.Email == "" {
+		p, err := s.storeFor(ctx).GetPerson(req.Id)
+		if err == ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "person %d not found", req.Id)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "getting person %d: %v", req.Id, err)
+		}
+		person = p
+	} else {
+		persons, err := s.storeFor(ctx).PersonsByEmail(req.Email)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "finding person with email %q: %v", req.Email, err)
+		}
+		if len(persons) == 0 {
+			return nil, status.Errorf(codes.NotFound, "person with email %q not found", req.Email)
+		}
+		person = persons[0]
+	}
+
+	for _, phone := range person.Phones {
+		if req.Type == nil || phone.Type == *req.Type {
+			return phone, nil
+		}
+	}
+	if req.Type == nil {
+		return nil, status.Errorf(codes.NotFound, "person %d has no phones", person.Id)
+	}
+	return nil, status.Errorf(codes.NotFound, "person %d has no %v phone", person.Id, *req.Type)
+}
+
+// ListPersons lists all persons with an address matching the given adress.
+func (s *PersonGuideServer) ListPersons(adress *pb.Adress, stream pb.PersonGuide_ListPersonsServer) error {
+	if err := s.checkLoaded(); err != nil {
+		return err
+	}
+	s.logger.DebugContext(stream.Context(), "Listing persons", "query", adress)
+	var sendErr error
+	err := s.storeFor(stream.Context()).ScanPersons(func(person *pb.Person) bool {
+		if !matchesAdress(person, adress) {
+			return true
+		}
+		sendErr = stream.Send(person)
+		return sendErr == nil
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return err
+}
+
+// RecordPersons records a list of sequence of persons.
+//
+// It gets a stream of persons, saving each one by id and replacing any
+// previous version, and responds with an address book holding the persons
+// recorded by this call. The first invalid person fails the call, leaving
+// the ones before it saved.
+func (s *PersonGuideServer) RecordPersons(stream pb.PersonGuide_RecordPersonsServer) error {
+	if err := s.checkLoaded(); err != nil {
+		return err
+	}
+	book := &pb.AddressBook{}
+	recorded := make(map[int32]int) // id to position in book.People
+	for {
+		person, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(book)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := validatePerson(person); err != nil {
+			return err
+		}
+		if _, err := s.upsertPerson(stream.Context(), person); err != nil {
+			return err
+		}
+
+		if i, ok := recorded[person.Id]; ok {
+			book.People[i] = person
+		} else {
+			recorded[person.Id] = len(book.People)
+			book.People = append(book.People, person)
+		}
+	}
+}
+
+// IngestPersons receives a stream of persons, saving each valid one by id,
+// and responds with the outcome of every person as it's processed. Once the
+// client is done sending, it responds with the totals.
+func (s *PersonGuideServer) IngestPersons(stream pb.PersonGuide_IngestPersonsServer) error {
+	if err := s.checkLoaded(); err != nil {
+		return err
+	}
+	summary := &pb.IngestSummary{}
+	for {
+		person, err := stream.Recv()
+		if err == io.EOF {
+			return stream.Send(&pb.IngestResponse{
+				Response: &pb.IngestResponse_Summary{Summary: summary},
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		result := &pb.PersonResult{Index: summary.Received, Id: person.Id}
+		summary.Received++
+		if err := validatePerson(person); err != nil {
+			result.Outcome = pb.PersonResult_REJECTED
+			result.Status = status.Convert(err).Proto()
+		} else if updated, err := s.upsertPerson(stream.Context(), person); err != nil {
+			result.Outcome = pb.PersonResult_REJECTED
+			result.Status = status.Convert(err).Proto()
+		} else if updated {
+			result.Outcome = pb.PersonResult_UPDATED
+		} else {
+			result.Outcome = pb.PersonResult_ACCEPTED
+		}
+
+		switch result.Outcome {
+		case pb.PersonResult_ACCEPTED:
+			summary.Accepted++
+		case pb.PersonResult_UPDATED:
+			summary.Updated++
+		default:
+			summary.Rejected++
+		}
+		err = stream.Send(&pb.IngestResponse{
+			Response: &pb.IngestResponse_Result{Result: result},
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// FindByPhone returns the persons having the given phone number.
+func (s *PersonGuideServer) FindByPhone(ctx context.Context, phone *pb.PhoneNumber) (*pb.AddressBook, error) {
+	if err := s.checkLoaded(); err != nil {
+		return nil, err
+	}
+	if normalizePhone(phone.Number) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid phone number %q", phone.Number)
+	}
+	persons, err := s.storeFor(ctx).PersonsByPhone(phone.Number)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "finding persons with phone %q: %v", phone.Number, err)
+	}
+	return &pb.AddressBook{People: persons}, nil
+}
+
+// upsertPerson stamps the person and saves it, replacing any person with the
+// same id. It reports whether a person was replaced.
+func (s *PersonGuideServer) upsertPerson(ctx context.Context, person *pb.Person) (bool, error) {
+	person.LastUpdated = timestamppb.New(s.now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.storeFor(ctx).GetPerson(person.Id)
+	if err != nil && err != ErrNotFound {
+		return false, status.Errorf(codes.Internal, "getting person %d: %v", person.Id, err)
+	}
+	exists := err == nil
+	if err := s.storeFor(ctx).PutPerson(person); err != nil {
+		return false, status.Errorf(codes.Internal, "saving person %d: %v", person.Id, err)
+	}
+	return exists, nil
+}
+
+// validatePerson checks that the person can be saved as is, returning an
+// InvalidArgument error describing the first problem found.
+func validatePerson(person *pb.Person) error {
+	if person.Id <= 0 {
+		return status.Errorf(codes.InvalidArgument, "id must be positive, got %d", person.Id)
+	}
+	if person.Name == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+	if person.Email != "" && !strings.Contains(person.Email, "@") {
+		return status.Errorf(codes.InvalidArgument, "invalid email %q", person.Email)
+	}
+	for i, phone := range person.Phones {
+		if phone.Number == "" {
+			return status.Errorf(codes.InvalidArgument, "phone #%d has no number", i)
+		}
+	}
+	return nil
+}
+
+// RoutePhones receives a stream of message/persons data, and responds with a stream of all
+// phone numbers at each of those persons. The phones saved for a known person are sent,
+// otherwise the phones carried by the received person.
+func (s *PersonGuideServer) RoutePhones(stream pb.PersonGuide_RoutePhonesServer) error {
+	if err := s.checkLoaded(); err != nil {
+		return err
+	}
+	for {
+		person, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		phones := person.Phones
+		saved, err := s.storeFor(stream.Context()).GetPerson(person.Id)
+		if err == nil {
+			phones = saved.Phones
+		} else if err != ErrNotFound {
+			return err
+		}
+
+		for _, phone := range phones {
+			if err := stream.Send(phone); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetPerson returns the person with the given id.
+func (s *PersonGuideServer) GetPerson(ctx context.Context, req *pb.GetPersonRequest) (*pb.Person, error) {
+	if err := s.checkLoaded(); err != nil {
+		return nil, err
+	}
+	p, err := s.storeFor(ctx).GetPerson(req.Id)
+	if err == ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "person %d not found", req.Id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "getting person %d: %v", req.Id, err)
+	}
+	return p, nil
+}
+
+// CreatePerson saves a new person, assigning it an id never used before if it has none.
+// The person must be valid once it has an id, like the ones recorded.
+func (s *PersonGuideServer) CreatePerson(ctx context.Context, req *pb.CreatePersonRequest) (*pb.Person, error) {
+	if err := s.checkLoaded(); err != nil {
+		return nil, err
+	}
+	if req.Person == nil {
+		return nil, status.Error(codes.InvalidArgument, "person is required")
+	}
+	person := proto.Clone(req.Person).(*pb.Person)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if person.Id == 0 {
+		maxID, err := s.store.MaxPersonID()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "getting the last id: %v", err)
+		}
+		person.Id = maxID + 1
+	} else if _, err := s.storeFor(ctx).GetPerson(person.Id); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "person %d already exists", person.Id)
+	} else if err != ErrNotFound {
+		return nil, status.Errorf(codes.Internal, "getting person %d: %v", person.Id, err)
+	}
+	if err := validatePerson(person); err != nil {
+		return nil, err
+	}
+
+	person.LastUpdated = timestamppb.New(s.now())
+	if err := s.storeFor(ctx).PutPerson(person); err != nil {
+		return nil, status.Errorf(codes.Internal, "saving person %d: %v", person.Id, err)
+	}
+	return person, nil
+}
+
+// UpdatePerson replaces the fields in the update mask of an existing person,
+// as long as the updated person is still valid.
+func (s *PersonGuideServer) UpdatePerson(ctx context.Context, req *pb.UpdatePersonRequest) (*pb.Person, error) {
+	if err := s.checkLoaded(); err != nil {
+		return nil, err
+	}
+	if req.Person == nil {
+		return nil, status.Error(codes.InvalidArgument, "person is required")
+	}
+	paths := req.GetUpdateMask().GetPaths()
+	fields := req.Person.ProtoReflect().Descriptor().Fields()
+	if len(paths) == 0 {
+		for i := 0; i < fields.Len(); i++ {
+			if name := fields.Get(i).Name(); name != "id" {
+				paths = append(paths, string(name))
+			}
+		}
+	}
+	for _, path := range paths {
+		if path == "id" {
+			return nil, status.Error(codes.InvalidArgument, "the id of a person can't be updated")
+		}
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid update mask path %q", path)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	person, err := s.storeFor(ctx).GetPerson(req.Person.Id)
+	if err == ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "person %d not found", req.Person.Id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "getting person %d: %v", req.Person.Id, err)
+	}
+
+	src, dst := req.Person.ProtoReflect(), person.ProtoReflect()
+	for _, path := range paths {
+		fd := fields.ByName(protoreflect.Name(path))
+		if src.Has(fd) {
+			dst.Set(fd, src.Get(fd))
+		} else {
+			dst.Clear(fd)
+		}
+	}
+	if err := validatePerson(person); err != nil {
+		return nil, err
+	}
+	person.LastUpdated = timestamppb.New(s.now())
+	if err := s.storeFor(ctx).PutPerson(person); err != nil {
+		return nil, status.Errorf(codes.Internal, "saving person %d: %v", person.Id, err)
+	}
+	return person, nil
+}
+
+// DeletePerson removes the person with the given id.
+func (s *PersonGuideServer) DeletePerson(ctx context.Context, req *pb.DeletePersonRequest) (*emptypb.Empty, error) {
+	if err := s.checkLoaded(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.storeFor(ctx).DeletePerson(req.Id)
+	if err == ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "person %d not found", req.Id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "deleting person %d: %v", req.Id, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// personDB is the layout of the files read by Load. Persons and
+// address books are kept as raw messages so every record can be decoded with
+// protojson, which understands timestamps and enum names.
+type personDB struct {
+	Persons      []json.RawMessage            `json:"persons"`
+	AddressBooks map[string][]json.RawMessage `json:"address_books"`
+}
+
+// loadFeatures loads persons and address books from a JSON file, described
+// in Load, into the store.
+func (s *PersonGuideServer) loadFeatures(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var db personDB
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Misspelled keys would otherwise load no persons without any error.
+	dec.DisallowUnknownFields()
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = dec.Decode(&db.Persons)
+	} else {
+		err = dec.Decode(&db)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("%s: unexpected data after the persons", filePath)
+	}
+
+	ids := make(map[int32]int, len(db.Persons))
+	persons := make([]*pb.Person, 0, len(db.Persons))
+	for i, raw := range db.Persons {
+		person := &pb.Person{}
+		if err := protojson.Unmarshal(raw, person); err != nil {
+			return fmt.Errorf("%s: person #%d: %w", filePath, i, err)
+		}
+		if err := validatePerson(person); err != nil {
+			return fmt.Errorf("%s: person #%d: %s", filePath, i, status.Convert(err).Message())
+		}
+		if j, ok := ids[person.Id]; ok {
+			return fmt.Errorf("%s: person #%d: duplicate id %d, already used by person #%d", filePath, i, person.Id, j)
+		}
+		ids[person.Id] = i
+		persons = append(persons, person)
+	}
+
+	books := make(map[string][]*pb.AddressBook, len(db.AddressBooks))
+	for name, raws := range db.AddressBooks {
+		for i, raw := range raws {
+			book := &pb.AddressBook{}
+			if err := protojson.Unmarshal(raw, book); err != nil {
+				return fmt.Errorf("%s: address book %q #%d: %w", filePath, name, i, err)
+			}
+			books[name] = append(books[name], book)
+		}
+	}
+	if len(books) == 0 {
+		books["book"] = []*pb.AddressBook{{People: persons}}
+	}
+
+	return s.saveFeatures(persons, books)
+}
+
+// saveFeatures puts the persons and address books in the store.
+func (s *PersonGuideServer) saveFeatures(persons []*pb.Person, books map[string][]*pb.AddressBook) error {
+	for _, p := range persons {
+		if err := s.store.PutPerson(p); err != nil {
+			return err
+		}
+	}
+	for name, b := range books {
+		if err := s.store.PutAddressBooks(name, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,619 @@
+package guide
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+var testTime = time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+
+// newLoadedServer returns a loaded server with the given persons.
+func newLoadedServer(t *testing.T, persons ...*pb.Person) *PersonGuideServer {
+	t.Helper()
+	store := NewMemoryStore()
+	for _, p := range persons {
+		if err := store.PutPerson(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New(WithStore(store), WithClock(func() time.Time { return testTime }))
+	if err := s.Load(""); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// dialBufconn serves s on an in-memory listener, returning a client of it.
+func dialBufconn(t *testing.T, s *PersonGuideServer) pb.PersonGuideClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	s.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPersonGuideClient(conn)
+}
+
+func TestRegisterRejectsCallsUntilLoad(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.PutPerson(&pb.Person{Id: 1, Name: "Juan"}); err != nil {
+		t.Fatal(err)
+	}
+	s := New(WithStore(store))
+	client := dialBufconn(t, s)
+	ctx := context.Background()
+
+	if _, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: 1}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetPerson before Load: got %v, want Unavailable", err)
+	}
+	stream, err := client.ListPersons(ctx, &pb.Adress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("ListPersons before Load: got %v, want Unavailable", err)
+	}
+	if got := s.CountPersons(); got != 0 {
+		t.Errorf("CountPersons before Load = %d, want 0", got)
+	}
+
+	if err := s.Load(""); err != nil {
+		t.Fatal(err)
+	}
+	person, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("GetPerson after Load: %v", err)
+	}
+	if person.Name != "Juan" {
+		t.Errorf("GetPerson after Load = %v, want Juan", person)
+	}
+	if got := s.CountPersons(); got != 1 {
+		t.Errorf("CountPersons after Load = %d, want 1", got)
+	}
+}
+
+func TestRegisterChecksAuth(t *testing.T) {
+	var methods []string
+	auth := func(ctx context.Context, fullMethod string) (context.Context, error) {
+		methods = append(methods, fullMethod)
+		if fullMethod == "/personguide.PersonGuide/DeletePerson" {
+			return nil, status.Error(codes.PermissionDenied, "denied")
+		}
+		return ctx, nil
+	}
+	s := New(WithAuth(auth))
+	if err := s.Load(""); err != nil {
+		t.Fatal(err)
+	}
+	client := dialBufconn(t, s)
+	ctx := context.Background()
+
+	if _, err := client.DeletePerson(ctx, &pb.DeletePersonRequest{Id: 1}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("DeletePerson: got %v, want PermissionDenied", err)
+	}
+	if _, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: 1}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetPerson: got %v, want NotFound", err)
+	}
+	stream, err := client.RecordPersons(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		t.Errorf("RecordPersons: %v", err)
+	}
+	want := []string{
+		"/personguide.PersonGuide/DeletePerson",
+		"/personguide.PersonGuide/GetPerson",
+		"/personguide.PersonGuide/RecordPersons",
+	}
+	if len(methods) != len(want) {
+		t.Fatalf("checked %q, want %q", methods, want)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Errorf("checked %q, want %q", methods, want)
+		}
+	}
+}
+
+func TestUpdatePerson(t *testing.T) {
+	existing := &pb.Person{
+		Id:     1,
+		Name:   "Juan",
+		Email:  "juan@gmail.com",
+		Phones: []*pb.PhoneNumber{{Number: "1234", Type: pb.PhoneType_HOME}},
+	}
+	tests := []struct {
+		name   string
+		person *pb.Person
+		paths  []string
+		want   *pb.Person // nil if the update fails
+		code   codes.Code
+	}{
+		{
+			name:   "single field",
+			person: &pb.Person{Id: 1, Name: "Juan Pablo", Email: "ignored@gmail.com"},
+			paths:  []string{"name"},
+			want: &pb.Person{Id: 1, Name: "Juan Pablo", Email: "juan@gmail.com",
+				Phones: []*pb.PhoneNumber{{Number: "1234", Type: pb.PhoneType_HOME}}},
+		},
+		{
+			name:   "field cleared",
+			person: &pb.Person{Id: 1},
+			paths:  []string{"email", "phones"},
+			want:   &pb.Person{Id: 1, Name: "Juan"},
+		},
+		{
+			name:   "repeated field replaced",
+			person: &pb.Person{Id: 1, Phones: []*pb.PhoneNumber{{Number: "555", Type: pb.PhoneType_WORK}}},
+			paths:  []string{"phones"},
+			want: &pb.Person{Id: 1, Name: "Juan", Email: "juan@gmail.com",
+				Phones: []*pb.PhoneNumber{{Number: "555", Type: pb.PhoneType_WORK}}},
+		},
+		{
+			name:   "no mask replaces every field",
+			person: &pb.Person{Id: 1, Name: "Juan Pablo"},
+			want:   &pb.Person{Id: 1, Name: "Juan Pablo"},
+		},
+		{
+			name:   "id in the mask",
+			person: &pb.Person{Id: 1, Name: "Juan"},
+			paths:  []string{"id"},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "unknown path",
+			person: &pb.Person{Id: 1, Name: "Juan"},
+			paths:  []string{"nickname"},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "name cleared",
+			person: &pb.Person{Id: 1, Email: "juan@gmail.com"},
+			paths:  []string{"name", "email"},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "invalid email",
+			person: &pb.Person{Id: 1, Email: "nope"},
+			paths:  []string{"email"},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "unknown person",
+			person: &pb.Person{Id: 2, Name: "Gabriel"},
+			paths:  []string{"name"},
+			code:   codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newLoadedServer(t, existing)
+			req := &pb.UpdatePersonRequest{Person: tt.person}
+			if tt.paths != nil {
+				req.UpdateMask = &fieldmaskpb.FieldMask{Paths: tt.paths}
+			}
+			got, err := s.UpdatePerson(context.Background(), req)
+			if status.Code(err) != tt.code {
+				t.Fatalf("got error %v, want code %v", err, tt.code)
+			}
+
+			saved, err := s.GetPerson(context.Background(), &pb.GetPersonRequest{Id: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if !proto.Equal(saved, existing) {
+					t.Errorf("failed update saved %v, want %v", saved, existing)
+				}
+				return
+			}
+			if got.LastUpdated.AsTime() != testTime {
+				t.Errorf("last updated %v, want %v", got.LastUpdated.AsTime(), testTime)
+			}
+			got.LastUpdated = nil
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			saved.LastUpdated = nil
+			if !proto.Equal(saved, tt.want) {
+				t.Errorf("saved %v, want %v", saved, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePerson(t *testing.T) {
+	s := newLoadedServer(t, &pb.Person{Id: 5, Name: "Brian"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		person *pb.Person
+		id     int32
+		code   codes.Code
+	}{
+		{"next id", &pb.Person{Name: "Kevin"}, 6, codes.OK},
+		{"given id", &pb.Person{Id: 20, Name: "Ryan"}, 20, codes.OK},
+		{"next id after the given one", &pb.Person{Name: "May"}, 21, codes.OK},
+		{"existing id", &pb.Person{Id: 5, Name: "Brian"}, 0, codes.AlreadyExists},
+		{"negative id", &pb.Person{Id: -7, Name: "Rosario"}, 0, codes.InvalidArgument},
+		{"no name", &pb.Person{Email: "nobody@gmail.com"}, 0, codes.InvalidArgument},
+		{"invalid email", &pb.Person{Name: "Rosario", Email: "nope"}, 0, codes.InvalidArgument},
+		{"phone without number", &pb.Person{Name: "Rosario", Phones: []*pb.PhoneNumber{{}}}, 0, codes.InvalidArgument},
+		{"no person", nil, 0, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		got, err := s.CreatePerson(ctx, &pb.CreatePersonRequest{Person: tt.person})
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: got error %v, want code %v", tt.name, err, tt.code)
+			continue
+		}
+		if err == nil && got.Id != tt.id {
+			t.Errorf("%s: got id %d, want %d", tt.name, got.Id, tt.id)
+		}
+	}
+
+	// Deleted ids aren't given again.
+	if _, err := s.DeletePerson(ctx, &pb.DeletePersonRequest{Id: 21}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.CreatePerson(ctx, &pb.CreatePersonRequest{Person: &pb.Person{Name: "Argentina"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != 22 {
+		t.Errorf("after deleting the last person got id %d, want 22", got.Id)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		persons int
+		books   []string
+		wantErr bool
+	}{
+		{
+			name:    "array of persons",
+			content: `[{"id": 1, "name": "Juan", "lastUpdated": "2023-04-01T10:00:00Z", "phones": [{"number": "1234", "type": "HOME"}]}]`,
+			persons: 1,
+			books:   []string{"book"},
+		},
+		{
+			name:    "object without address books",
+			content: `{"persons": [{"id": 1, "name": "Juan"}, {"id": 2, "name": "Gabriel"}]}`,
+			persons: 2,
+			books:   []string{"book"},
+		},
+		{
+			name:    "named address books",
+			content: `{"persons": [{"id": 1, "name": "Juan"}], "address_books": {"friends": [{"people": [{"id": 1, "name": "Juan"}]}]}}`,
+			persons: 1,
+			books:   []string{"friends"},
+		},
+		{name: "misspelled key", content: `{"person": [{"id": 1, "name": "Juan"}]}`, wantErr: true},
+		{name: "unknown person field", content: `[{"id": 1, "name": "Juan", "nickname": "J"}]`, wantErr: true},
+		{name: "zero id", content: `[{"name": "Juan"}]`, wantErr: true},
+		{name: "negative id", content: `[{"id": -3, "name": "Juan"}]`, wantErr: true},
+		{name: "no name", content: `[{"id": 1}]`, wantErr: true},
+		{name: "phone without number", content: `[{"id": 1, "name": "Juan", "phones": [{"number": ""}]}]`, wantErr: true},
+		{name: "duplicate id", content: `[{"id": 1, "name": "Juan"}, {"id": 1, "name": "Gabriel"}]`, wantErr: true},
+		{name: "trailing data", content: `[{"id": 1, "name": "Juan"}] []`, wantErr: true},
+		{name: "not JSON", content: `persons: []`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "persons.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			store := NewMemoryStore()
+			err := New(WithStore(store)).Load(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Load succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			persons, err := store.ListPersons()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(persons) != tt.persons {
+				t.Errorf("loaded %d persons, want %d", len(persons), tt.persons)
+			}
+			books, err := store.ListAddressBooks()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(books) != len(tt.books) || (len(books) > 0 && books[0] != tt.books[0]) {
+				t.Errorf("loaded address books %q, want %q", books, tt.books)
+			}
+		})
+	}
+}
+
+func TestLoadExampleFile(t *testing.T) {
+	s := New()
+	if err := s.Load(filepath.Join("..", "data", "persons.json")); err != nil {
+		t.Fatal(err)
+	}
+	if s.CountPersons() == 0 {
+		t.Error("no persons loaded from data/persons.json")
+	}
+}
+
+func TestCreatePersonAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "persons.db")
+	ctx := context.Background()
+	for i, want := range []int32{1, 2, 3} {
+		store, err := NewBoltStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := New(WithStore(store))
+		if err := s.Load(""); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.CreatePerson(ctx, &pb.CreatePersonRequest{Person: &pb.Person{Name: "Juan"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Id != want {
+			t.Errorf("start #%d: got id %d, want %d", i, got.Id, want)
+		}
+		// The last person is deleted, its id must not be given again.
+		if _, err := s.DeletePerson(ctx, &pb.DeletePersonRequest{Id: got.Id}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetPhone(t *testing.T) {
+	home := &pb.PhoneNumber{Number: "1234", Type: pb.PhoneType_HOME}
+	work := &pb.PhoneNumber{Number: "4321", Type: pb.PhoneType_WORK}
+	client := dialBufconn(t, newLoadedServer(t,
+		&pb.Person{Id: 1, Name: "Juan", Email: "juan@gmail.com", Phones: []*pb.PhoneNumber{home, work}},
+		&pb.Person{Id: 2, Name: "Gabriel", Email: "gabriel@gmail.com"},
+	))
+	tests := []struct {
+		name string
+		req  *pb.GetPhoneRequest
+		want *pb.PhoneNumber
+		code codes.Code
+	}{
+		{"by id", &pb.GetPhoneRequest{Id: 1}, home, codes.OK},
+		{"by id and type", &pb.GetPhoneRequest{Id: 1, Type: pb.PhoneType_WORK.Enum()}, work, codes.OK},
+		{"by email", &pb.GetPhoneRequest{Email: "Juan@Gmail.com"}, home, codes.OK},
+		{"by email and type", &pb.GetPhoneRequest{Email: "juan@gmail.com", Type: pb.PhoneType_WORK.Enum()}, work, codes.OK},
+		{"id over email", &pb.GetPhoneRequest{Id: 1, Email: "gabriel@gmail.com"}, home, codes.OK},
+		{"unknown id", &pb.GetPhoneRequest{Id: 3}, nil, codes.NotFound},
+		{"unknown email", &pb.GetPhoneRequest{Email: "nobody@gmail.com"}, nil, codes.NotFound},
+		{"no phone of the type", &pb.GetPhoneRequest{Id: 1, Type: pb.PhoneType_MOBILE.Enum()}, nil, codes.NotFound},
+		{"no phones", &pb.GetPhoneRequest{Id: 2}, nil, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetPhone(context.Background(), tt.req)
+			if status.Code(err) != tt.code {
+				t.Fatalf("got error %v, want code %v", err, tt.code)
+			}
+			if tt.want != nil && !proto.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordPersons(t *testing.T) {
+	s := newLoadedServer(t, &pb.Person{Id: 1, Name: "Juan", Email: "juan@gmail.com"})
+	client := dialBufconn(t, s)
+	ctx := context.Background()
+
+	stream, err := client.RecordPersons(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*pb.Person{
+		{Id: 2, Name: "Gabriel"},
+		{Id: 1, Name: "Juan Pablo"},
+		{Id: 2, Name: "Gabriel Jr"},
+	} {
+		if err := stream.Send(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	book, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the persons of this call, each once, in the order first received,
+	// with the last version received.
+	var names []string
+	for _, p := range book.People {
+		names = append(names, p.Name)
+		if p.LastUpdated.AsTime() != testTime {
+			t.Errorf("person %d last updated %v, want %v", p.Id, p.LastUpdated.AsTime(), testTime)
+		}
+	}
+	if got, want := strings.Join(names, ","), "Gabriel Jr,Juan Pablo"; got != want {
+		t.Errorf("recorded %q, want %q", got, want)
+	}
+	saved, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "Juan Pablo" || saved.Email != "" {
+		t.Errorf("saved %v, want the person replaced by the one recorded", saved)
+	}
+	if got := s.CountPersons(); got != 2 {
+		t.Errorf("CountPersons() = %d, want 2", got)
+	}
+}
+
+func TestRecordPersonsStopsAtInvalidPerson(t *testing.T) {
+	s := newLoadedServer(t)
+	client := dialBufconn(t, s)
+	ctx := context.Background()
+
+	stream, err := client.RecordPersons(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*pb.Person{
+		{Id: 1, Name: "Juan"},
+		{Id: 2},
+		{Id: 3, Name: "Albert"},
+	} {
+		if err := stream.Send(p); err != nil {
+			// The server may have failed the call already.
+			break
+		}
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument", err)
+	}
+	if _, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: 1}); err != nil {
+		t.Errorf("person before the invalid one: %v, want it saved", err)
+	}
+	if _, err := client.GetPerson(ctx, &pb.GetPersonRequest{Id: 3}); status.Code(err) != codes.NotFound {
+		t.Errorf("person after the invalid one: got %v, want NotFound", err)
+	}
+}
+
+func TestIngestPersons(t *testing.T) {
+	s := newLoadedServer(t, &pb.Person{Id: 1, Name: "Juan"})
+	client := dialBufconn(t, s)
+
+	stream, err := client.IngestPersons(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := []*pb.Person{
+		{Id: 2, Name: "Gabriel"},
+		{Id: 1, Name: "Juan Pablo"},
+		{Id: 3, Email: "no-name@gmail.com"},
+		{Id: 2, Name: "Gabriel Jr"},
+		{Id: -4, Name: "Negative"},
+	}
+	for _, p := range sent {
+		if err := stream.Send(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []*pb.PersonResult
+	var summary *pb.IngestSummary
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := resp.GetResult(); r != nil {
+			results = append(results, r)
+		} else {
+			summary = resp.GetSummary()
+		}
+	}
+
+	want := []struct {
+		id      int32
+		outcome pb.PersonResult_Outcome
+	}{
+		{2, pb.PersonResult_ACCEPTED},
+		{1, pb.PersonResult_UPDATED},
+		{3, pb.PersonResult_REJECTED},
+		{2, pb.PersonResult_UPDATED},
+		{-4, pb.PersonResult_REJECTED},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.Index != int64(i) || r.Id != want[i].id || r.Outcome != want[i].outcome {
+			t.Errorf("result #%d = %v, want id %d %v", i, r, want[i].id, want[i].outcome)
+		}
+		rejected := r.Outcome == pb.PersonResult_REJECTED
+		if rejected && codes.Code(r.GetStatus().GetCode()) != codes.InvalidArgument {
+			t.Errorf("result #%d status = %v, want InvalidArgument", i, r.Status)
+		}
+		if !rejected && r.Status != nil {
+			t.Errorf("result #%d status = %v, want none", i, r.Status)
+		}
+	}
+	wantSummary := &pb.IngestSummary{Received: 5, Accepted: 1, Updated: 2, Rejected: 2}
+	if !proto.Equal(summary, wantSummary) {
+		t.Errorf("summary = %v, want %v", summary, wantSummary)
+	}
+	if got := s.CountPersons(); got != 2 {
+		t.Errorf("CountPersons() = %d, want 2", got)
+	}
+}
+
+func TestFindByPhone(t *testing.T) {
+	client := dialBufconn(t, newLoadedServer(t,
+		&pb.Person{Id: 1, Name: "Juan", Phones: []*pb.PhoneNumber{{Number: "+54 (11) 1234-5678"}}},
+		&pb.Person{Id: 2, Name: "Gabriel", Phones: []*pb.PhoneNumber{{Number: "4321"}, {Number: "54 11 1234 5678"}}},
+		&pb.Person{Id: 3, Name: "Albert", Phones: []*pb.PhoneNumber{{Number: "4321"}}},
+	))
+	tests := []struct {
+		number string
+		want   []int32
+		code   codes.Code
+	}{
+		{"541112345678", []int32{1, 2}, codes.OK},
+		{"+54-11-1234-5678", []int32{1, 2}, codes.OK},
+		{"4321", []int32{2, 3}, codes.OK},
+		{"1234", []int32{}, codes.OK},
+		{"", nil, codes.InvalidArgument},
+		{"not a number", nil, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		book, err := client.FindByPhone(context.Background(), &pb.PhoneNumber{Number: tt.number})
+		if status.Code(err) != tt.code {
+			t.Errorf("FindByPhone(%q): got error %v, want code %v", tt.number, err, tt.code)
+			continue
+		}
+		if err == nil && !equalIDs(personIDs(book.People), tt.want) {
+			t.Errorf("FindByPhone(%q) = %v, want ids %v", tt.number, personIDs(book.People), tt.want)
+		}
+	}
+}
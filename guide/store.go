@@ -0,0 +1,205 @@
+package guide
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// ErrNotFound is returned by a Store when the requested person or address
+// book does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Store is the storage used by a PersonGuideServer to keep persons and
+// named address books.
+//
+// Implementations must be safe for concurrent use. Messages passed to and
+// returned from a Store are never shared with it, so callers are free to
+// modify them.
+type Store interface {
+	// GetPerson returns the person with the given id, or ErrNotFound.
+	GetPerson(id int32) (*pb.Person, error)
+	// PutPerson stores the person, replacing any person with the same id.
+	PutPerson(person *pb.Person) error
+	// DeletePerson removes the person with the given id, or returns ErrNotFound.
+	DeletePerson(id int32) error
+	// ListPersons returns all persons ordered by id.
+	ListPersons() ([]*pb.Person, error)
+	// ScanPersons calls fn for every person ordered by id, until fn returns false.
+	ScanPersons(fn func(*pb.Person) bool) error
+	// MaxPersonID returns the largest id of the persons ever stored, including
+	// the ones deleted since, or 0 if none.
+	MaxPersonID() (int32, error)
+
+	// GetAddressBooks returns the address books saved under name, or ErrNotFound.
+	GetAddressBooks(name string) ([]*pb.AddressBook, error)
+	// PutAddressBooks stores the address books under name, replacing any previous ones.
+	PutAddressBooks(name string, books []*pb.AddressBook) error
+	// DeleteAddressBooks removes the address books saved under name, or returns ErrNotFound.
+	DeleteAddressBooks(name string) error
+	// ListAddressBooks returns the names of all address books, sorted.
+	ListAddressBooks() ([]string, error)
+	// ScanAddressBooks calls fn for every name and its address books, sorted by
+	// name, until fn returns false.
+	ScanAddressBooks(fn func(name string, books []*pb.AddressBook) bool) error
+
+	// Close flushes and releases the resources held by the store.
+	Close() error
+}
+
+// memoryStore is a Store that keeps everything in memory, so its content is
+// lost when the server stops.
+type memoryStore struct {
+	mu           sync.RWMutex
+	persons      map[int32]*pb.Person
+	maxID        int32
+	addressBooks map[string][]*pb.AddressBook
+}
+
+// NewMemoryStore returns an empty Store kept in memory.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		persons:      make(map[int32]*pb.Person),
+		addressBooks: make(map[string][]*pb.AddressBook),
+	}
+}
+
+func (m *memoryStore) GetPerson(id int32) (*pb.Person, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.persons[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(p).(*pb.Person), nil
+}
+
+func (m *memoryStore) PutPerson(person *pb.Person) error {
+	p := proto.Clone(person).(*pb.Person)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.persons[p.Id] = p
+	if p.Id > m.maxID {
+		m.maxID = p.Id
+	}
+	return nil
+}
+
+func (m *memoryStore) DeletePerson(id int32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.persons[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.persons, id)
+	return nil
+}
+
+func (m *memoryStore) ListPersons() ([]*pb.Person, error) {
+	var persons []*pb.Person
+	err := m.ScanPersons(func(p *pb.Person) bool {
+		persons = append(persons, p)
+		return true
+	})
+	return persons, err
+}
+
+func (m *memoryStore) ScanPersons(fn func(*pb.Person) bool) error {
+	// Copy the persons first so fn can call back into the store.
+	m.mu.RLock()
+	persons := make([]*pb.Person, 0, len(m.persons))
+	for _, p := range m.persons {
+		persons = append(persons, proto.Clone(p).(*pb.Person))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(persons, func(i, j int) bool { return persons[i].Id < persons[j].Id })
+	for _, p := range persons {
+		if !fn(p) {
+			break
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) MaxPersonID() (int32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.maxID, nil
+}
+
+func (m *memoryStore) GetAddressBooks(name string) ([]*pb.AddressBook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	books, ok := m.addressBooks[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneAddressBooks(books), nil
+}
+
+func (m *memoryStore) PutAddressBooks(name string, books []*pb.AddressBook) error {
+	books = cloneAddressBooks(books)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addressBooks[name] = books
+	return nil
+}
+
+func (m *memoryStore) DeleteAddressBooks(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.addressBooks[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.addressBooks, name)
+	return nil
+}
+
+func (m *memoryStore) ListAddressBooks() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.addressBooks))
+	for name := range m.addressBooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memoryStore) ScanAddressBooks(fn func(name string, books []*pb.AddressBook) bool) error {
+	names, err := m.ListAddressBooks()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		books, err := m.GetAddressBooks(name)
+		if err == ErrNotFound {
+			// Deleted while scanning.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(name, books) {
+			break
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+func cloneAddressBooks(books []*pb.AddressBook) []*pb.AddressBook {
+	c := make([]*pb.AddressBook, len(books))
+	for i, b := range books {
+		c[i] = proto.Clone(b).(*pb.AddressBook)
+	}
+	return c
+}
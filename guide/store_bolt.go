@@ -0,0 +1,276 @@
+package guide
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+var (
+	personsBucket      = []byte("persons")
+	addressBooksBucket = []byte("address_books")
+	metaBucket         = []byte("meta")
+
+	maxPersonIDKey = []byte("max_person_id")
+)
+
+// boltStore is a Store that keeps persons and address books in a bolt
+// database file, so they survive restarts.
+//
+// Persons are kept in the "persons" bucket keyed by id. Every address book
+// name has its own bucket inside "address_books", keyed by the position of
+// the address book in the list. The "meta" bucket keeps the largest id ever
+// stored, so the ids of deleted persons aren't given again after a restart.
+type boltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens the bolt database in path, creating it if needed. It
+// can't be opened by another process until closed.
+func NewBoltStore(path string) (Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{personsBucket, addressBooksBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+// personKey encodes an id so keys sort in the same order as ids, negative
+// ones included.
+func personKey(id int32) []byte {
+	k := make([]byte, 4)
+	binary.BigEndian.PutUint32(k, uint32(id)^1<<31)
+	return k
+}
+
+func indexKey(i int) []byte {
+	k := make([]byte, 4)
+	binary.BigEndian.PutUint32(k, uint32(i))
+	return k
+}
+
+func (b *boltStore) GetPerson(id int32) (*pb.Person, error) {
+	person := &pb.Person{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(personsBucket).Get(personKey(id))
+		if v == nil {
+			return ErrNotFound
+		}
+		return proto.Unmarshal(v, person)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return person, nil
+}
+
+func (b *boltStore) PutPerson(person *pb.Person) error {
+	v, err := proto.Marshal(person)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(personsBucket).Put(personKey(person.Id), v); err != nil {
+			return err
+		}
+		meta := tx.Bucket(metaBucket)
+		if max := readMaxPersonID(meta); person.Id <= max {
+			return nil
+		}
+		k := make([]byte, 4)
+		binary.BigEndian.PutUint32(k, uint32(person.Id))
+		return meta.Put(maxPersonIDKey, k)
+	})
+}
+
+func (b *boltStore) MaxPersonID() (int32, error) {
+	var max int32
+	err := b.db.View(func(tx *bolt.Tx) error {
+		max = readMaxPersonID(tx.Bucket(metaBucket))
+		return nil
+	})
+	return max, err
+}
+
+// readMaxPersonID returns the largest id saved in the meta bucket, or 0 for
+// databases written before it was kept.
+func readMaxPersonID(meta *bolt.Bucket) int32 {
+	v := meta.Get(maxPersonIDKey)
+	if len(v) != 4 {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(v))
+}
+
+func (b *boltStore) DeletePerson(id int32) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(personsBucket)
+		k := personKey(id)
+		if bucket.Get(k) == nil {
+			return ErrNotFound
+		}
+		return bucket.Delete(k)
+	})
+}
+
+func (b *boltStore) ListPersons() ([]*pb.Person, error) {
+	var persons []*pb.Person
+	err := b.ScanPersons(func(p *pb.Person) bool {
+		persons = append(persons, p)
+		return true
+	})
+	return persons, err
+}
+
+func (b *boltStore) ScanPersons(fn func(*pb.Person) bool) error {
+	// Decode everything before calling fn, so fn is free to write to the
+	// store without deadlocking on the read transaction.
+	var persons []*pb.Person
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(personsBucket).ForEach(func(_, v []byte) error {
+			p := &pb.Person{}
+			if err := proto.Unmarshal(v, p); err != nil {
+				return err
+			}
+			persons = append(persons, p)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range persons {
+		if !fn(p) {
+			break
+		}
+	}
+	return nil
+}
+
+func (b *boltStore) GetAddressBooks(name string) ([]*pb.AddressBook, error) {
+	var books []*pb.AddressBook
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(addressBooksBucket).Bucket([]byte(name))
+		if bucket == nil {
+			return ErrNotFound
+		}
+		var err error
+		books, err = readAddressBooks(bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (b *boltStore) PutAddressBooks(name string, books []*pb.AddressBook) error {
+	values := make([][]byte, len(books))
+	for i, book := range books {
+		v, err := proto.Marshal(book)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		parent := tx.Bucket(addressBooksBucket)
+		if parent.Bucket([]byte(name)) != nil {
+			if err := parent.DeleteBucket([]byte(name)); err != nil {
+				return err
+			}
+		}
+		bucket, err := parent.CreateBucket([]byte(name))
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			if err := bucket.Put(indexKey(i), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltStore) DeleteAddressBooks(name string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(addressBooksBucket).DeleteBucket([]byte(name))
+		if err == bolt.ErrBucketNotFound {
+			return ErrNotFound
+		}
+		return err
+	})
+}
+
+func (b *boltStore) ListAddressBooks() ([]string, error) {
+	var names []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(addressBooksBucket).ForEach(func(k, _ []byte) error {
+			names = append(names, string(k))
+			return nil
+		})
+	})
+	return names, err
+}
+
+func (b *boltStore) ScanAddressBooks(fn func(name string, books []*pb.AddressBook) bool) error {
+	type named struct {
+		name  string
+		books []*pb.AddressBook
+	}
+	var all []named
+	err := b.db.View(func(tx *bolt.Tx) error {
+		parent := tx.Bucket(addressBooksBucket)
+		return parent.ForEach(func(k, _ []byte) error {
+			books, err := readAddressBooks(parent.Bucket(k))
+			if err != nil {
+				return err
+			}
+			all = append(all, named{name: string(k), books: books})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, n := range all {
+		if !fn(n.name, n.books) {
+			break
+		}
+	}
+	return nil
+}
+
+func (b *boltStore) Close() error {
+	return b.db.Close()
+}
+
+func readAddressBooks(bucket *bolt.Bucket) ([]*pb.AddressBook, error) {
+	books := []*pb.AddressBook{}
+	err := bucket.ForEach(func(_, v []byte) error {
+		book := &pb.AddressBook{}
+		if err := proto.Unmarshal(v, book); err != nil {
+			return err
+		}
+		books = append(books, book)
+		return nil
+	})
+	return books, err
+}
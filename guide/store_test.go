@@ -0,0 +1,211 @@
+package guide
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// testStores runs test with every Store implementation, each one empty.
+func testStores(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryStore())
+	})
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStore(filepath.Join(t.TempDir(), "persons.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		test(t, store)
+	})
+}
+
+func personIDs(persons []*pb.Person) []int32 {
+	ids := make([]int32, len(persons))
+	for i, p := range persons {
+		ids[i] = p.Id
+	}
+	return ids
+}
+
+func equalIDs(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStorePersons(t *testing.T) {
+	testStores(t, func(t *testing.T, store Store) {
+		if _, err := store.GetPerson(1); err != ErrNotFound {
+			t.Errorf("GetPerson on an empty store: got %v, want ErrNotFound", err)
+		}
+		for _, id := range []int32{3, -2, 1, 300, -70000} {
+			if err := store.PutPerson(&pb.Person{Id: id, Name: "v1"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := store.PutPerson(&pb.Person{Id: 1, Name: "v2"}); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := store.GetPerson(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Name != "v2" {
+			t.Errorf("GetPerson(1) = %v, want the replaced person", p)
+		}
+		// The person returned isn't shared with the store.
+		p.Name = "changed"
+		if p, _ := store.GetPerson(1); p.Name != "v2" {
+			t.Errorf("changing a returned person changed the store to %v", p)
+		}
+
+		persons, err := store.ListPersons()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := personIDs(persons), []int32{-70000, -2, 1, 3, 300}; !equalIDs(got, want) {
+			t.Errorf("ListPersons() ids = %v, want %v", got, want)
+		}
+
+		var scanned []int32
+		err = store.ScanPersons(func(p *pb.Person) bool {
+			scanned = append(scanned, p.Id)
+			return len(scanned) < 2
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int32{-70000, -2}; !equalIDs(scanned, want) {
+			t.Errorf("ScanPersons stopping after 2 got %v, want %v", scanned, want)
+		}
+
+		if err := store.DeletePerson(300); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeletePerson(300); err != ErrNotFound {
+			t.Errorf("deleting a deleted person: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetPerson(300); err != ErrNotFound {
+			t.Errorf("GetPerson of a deleted person: got %v, want ErrNotFound", err)
+		}
+		if max, err := store.MaxPersonID(); err != nil || max != 300 {
+			t.Errorf("MaxPersonID() after deleting the last person = %d, %v, want 300", max, err)
+		}
+	})
+}
+
+func TestStoreAddressBooks(t *testing.T) {
+	testStores(t, func(t *testing.T, store Store) {
+		if _, err := store.GetAddressBooks("friends"); err != ErrNotFound {
+			t.Errorf("GetAddressBooks on an empty store: got %v, want ErrNotFound", err)
+		}
+		if err := store.DeleteAddressBooks("friends"); err != ErrNotFound {
+			t.Errorf("DeleteAddressBooks on an empty store: got %v, want ErrNotFound", err)
+		}
+
+		juan := &pb.Person{Id: 1, Name: "Juan"}
+		gabriel := &pb.Person{Id: 2, Name: "Gabriel"}
+		books := []*pb.AddressBook{{People: []*pb.Person{juan}}, {People: []*pb.Person{gabriel}}, {}}
+		if err := store.PutAddressBooks("work", books); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutAddressBooks("friends", books[:1]); err != nil {
+			t.Fatal(err)
+		}
+		// Replacing with fewer books drops the ones left.
+		replaced := []*pb.AddressBook{{People: []*pb.Person{gabriel}}}
+		if err := store.PutAddressBooks("work", replaced); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := store.GetAddressBooks("work")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || !proto.Equal(got[0], replaced[0]) {
+			t.Errorf("GetAddressBooks(work) = %v, want %v", got, replaced)
+		}
+
+		names, err := store.ListAddressBooks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(names, ",") != "friends,work" {
+			t.Errorf("ListAddressBooks() = %q, want sorted names", names)
+		}
+
+		var scanned []string
+		err = store.ScanAddressBooks(func(name string, books []*pb.AddressBook) bool {
+			scanned = append(scanned, name)
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(scanned, ",") != "friends,work" {
+			t.Errorf("ScanAddressBooks got %q, want sorted names", scanned)
+		}
+
+		if err := store.DeleteAddressBooks("friends"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetAddressBooks("friends"); err != ErrNotFound {
+			t.Errorf("GetAddressBooks of deleted books: got %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestBoltStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "persons.db")
+	store, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int32{1, 7} {
+		if err := store.PutPerson(&pb.Person{Id: id, Name: "Juan"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.DeletePerson(7); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutAddressBooks("book", []*pb.AddressBook{{People: []*pb.Person{{Id: 1, Name: "Juan"}}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	persons, err := store.ListPersons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := personIDs(persons); !equalIDs(got, []int32{1}) {
+		t.Errorf("persons after reopening = %v, want [1]", got)
+	}
+	books, err := store.GetAddressBooks("book")
+	if err != nil || len(books) != 1 || len(books[0].People) != 1 {
+		t.Errorf("address books after reopening = %v, %v, want the book saved", books, err)
+	}
+	if max, err := store.MaxPersonID(); err != nil || max != 7 {
+		t.Errorf("MaxPersonID() after reopening = %d, %v, want 7", max, err)
+	}
+}
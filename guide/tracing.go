@@ -0,0 +1,82 @@
+package guide
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+var tracer = otel.Tracer("github.com/jackgris/go-grpc-communication/guide")
+
+// tracedStore records a span for every operation on the store, as a child
+// of the span of the call that made it.
+type tracedStore struct {
+	*indexedStore
+	ctx context.Context
+}
+
+// storeFor returns the store of the server, tracing its operations in ctx.
+func (s *PersonGuideServer) storeFor(ctx context.Context) tracedStore {
+	return tracedStore{indexedStore: s.store, ctx: ctx}
+}
+
+// start starts the span of an operation, which is ended by calling the
+// returned function with its error.
+func (t tracedStore) start(op string, attrs ...attribute.KeyValue) func(error) {
+	_, span := tracer.Start(t.ctx, "store."+op,
+		trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+	return func(err error) {
+		if err != nil && err != ErrNotFound {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (t tracedStore) GetPerson(id int32) (*pb.Person, error) {
+	end := t.start("GetPerson", attribute.Int("person.id", int(id)))
+	p, err := t.indexedStore.GetPerson(id)
+	end(err)
+	return p, err
+}
+
+func (t tracedStore) PutPerson(person *pb.Person) error {
+	end := t.start("PutPerson", attribute.Int("person.id", int(person.Id)))
+	err := t.indexedStore.PutPerson(person)
+	end(err)
+	return err
+}
+
+func (t tracedStore) DeletePerson(id int32) error {
+	end := t.start("DeletePerson", attribute.Int("person.id", int(id)))
+	err := t.indexedStore.DeletePerson(id)
+	end(err)
+	return err
+}
+
+func (t tracedStore) ScanPersons(fn func(*pb.Person) bool) error {
+	end := t.start("ScanPersons")
+	err := t.indexedStore.ScanPersons(fn)
+	end(err)
+	return err
+}
+
+func (t tracedStore) PersonsByEmail(email string) ([]*pb.Person, error) {
+	end := t.start("PersonsByEmail")
+	persons, err := t.indexedStore.PersonsByEmail(email)
+	end(err)
+	return persons, err
+}
+
+func (t tracedStore) PersonsByPhone(number string) ([]*pb.Person, error) {
+	end := t.start("PersonsByPhone")
+	persons, err := t.indexedStore.PersonsByPhone(number)
+	end(err)
+	return persons, err
+}
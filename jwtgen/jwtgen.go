@@ -0,0 +1,129 @@
+// Package main implements a command that creates the signing key used for
+// bearer tokens, and signs tokens with it, so the JWT authentication of the
+// person guide server can be used without an identity provider.
+//
+// Run it with -keygen to write a new ECDSA P-256 key and the JWKS file the
+// server reads with -jwks_file. Without -keygen it prints a token signed with
+// the key.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/jackgris/go-grpc-communication/data"
+)
+
+var (
+	keygen   = flag.Bool("keygen", false, "Create a new signing key and JWKS file instead of a token")
+	keyFile  = flag.String("key_file", "", "The file with the ECDSA private key that signs tokens")
+	jwksFile = flag.String("jwks_file", "", "The JWKS file written with -keygen")
+	subject  = flag.String("sub", "test-client1", "The subject of the token, known as token:<sub> in authorization policies")
+	issuer   = flag.String("iss", "jwtgen", "The issuer of the token")
+	audience = flag.String("aud", "personguide", "The audience of the token")
+	ttl      = flag.Duration("ttl", time.Hour, "How long the token is valid")
+)
+
+func main() {
+	flag.Parse()
+	if *keyFile == "" {
+		*keyFile = data.Path("jwt/jwt_key.pem")
+	}
+	if *jwksFile == "" {
+		*jwksFile = data.Path("jwt/jwks.json")
+	}
+
+	if *keygen {
+		if err := writeKey(*keyFile, *jwksFile); err != nil {
+			log.Fatalf("Failed to create key: %v", err)
+		}
+		return
+	}
+	token, err := signToken(*keyFile)
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+	fmt.Println(token)
+}
+
+// writeKey creates a new key, writing it in PEM to keyFile and its public
+// part to jwksFile.
+func writeKey(keyFile, jwksFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return err
+	}
+
+	size := (key.Curve.Params().BitSize + 7) / 8
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"crv": "P-256",
+			"use": "sig",
+			"alg": "ES256",
+			"kid": keyID(&key.PublicKey),
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}},
+	}
+	out, err := json.MarshalIndent(jwks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(jwksFile, append(out, '\n'), 0644)
+}
+
+// signToken returns a token signed with the key in keyFile, with the claims
+// given by flags.
+func signToken(keyFile string) (string, error) {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(b)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", keyFile, err)
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Subject:   *subject,
+		Issuer:    *issuer,
+		Audience:  jwt.ClaimStrings{*audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(*ttl)),
+	})
+	token.Header["kid"] = keyID(&key.PublicKey)
+	return token.SignedString(key)
+}
+
+// keyID derives the id of a key from its public part, so tokens and the JWKS
+// file agree on it without storing it.
+func keyID(pub *ecdsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		log.Fatalf("Failed to marshal public key: %v", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8])
+}
@@ -7,8 +7,11 @@
 package personguide
 
 import (
+	status "google.golang.org/genproto/googleapis/rpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -70,6 +73,107 @@ func (PhoneType) EnumDescriptor() ([]byte, []int) {
 	return file_person_guide_proto_rawDescGZIP(), []int{0}
 }
 
+type Address_Type int32
+
+const (
+	Address_OTHER Address_Type = 0
+	Address_HOME  Address_Type = 1
+	Address_WORK  Address_Type = 2
+)
+
+// Enum value maps for Address_Type.
+var (
+	Address_Type_name = map[int32]string{
+		0: "OTHER",
+		1: "HOME",
+		2: "WORK",
+	}
+	Address_Type_value = map[string]int32{
+		"OTHER": 0,
+		"HOME":  1,
+		"WORK":  2,
+	}
+)
+
+func (x Address_Type) Enum() *Address_Type {
+	p := new(Address_Type)
+	*p = x
+	return p
+}
+
+func (x Address_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Address_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_person_guide_proto_enumTypes[1].Descriptor()
+}
+
+func (Address_Type) Type() protoreflect.EnumType {
+	return &file_person_guide_proto_enumTypes[1]
+}
+
+func (x Address_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Address_Type.Descriptor instead.
+func (Address_Type) EnumDescriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{2, 0}
+}
+
+type PersonResult_Outcome int32
+
+const (
+	PersonResult_OUTCOME_UNSPECIFIED PersonResult_Outcome = 0
+	PersonResult_ACCEPTED            PersonResult_Outcome = 1 // A new person was saved.
+	PersonResult_UPDATED             PersonResult_Outcome = 2 // An existing person was replaced.
+	PersonResult_REJECTED            PersonResult_Outcome = 3 // The person wasn't saved, see status.
+)
+
+// Enum value maps for PersonResult_Outcome.
+var (
+	PersonResult_Outcome_name = map[int32]string{
+		0: "OUTCOME_UNSPECIFIED",
+		1: "ACCEPTED",
+		2: "UPDATED",
+		3: "REJECTED",
+	}
+	PersonResult_Outcome_value = map[string]int32{
+		"OUTCOME_UNSPECIFIED": 0,
+		"ACCEPTED":            1,
+		"UPDATED":             2,
+		"REJECTED":            3,
+	}
+)
+
+func (x PersonResult_Outcome) Enum() *PersonResult_Outcome {
+	p := new(PersonResult_Outcome)
+	*p = x
+	return p
+}
+
+func (x PersonResult_Outcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PersonResult_Outcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_person_guide_proto_enumTypes[2].Descriptor()
+}
+
+func (PersonResult_Outcome) Type() protoreflect.EnumType {
+	return &file_person_guide_proto_enumTypes[2]
+}
+
+func (x PersonResult_Outcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PersonResult_Outcome.Descriptor instead.
+func (PersonResult_Outcome) EnumDescriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{11, 0}
+}
+
 type Person struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -80,6 +184,7 @@ type Person struct {
 	Email       string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
 	Phones      []*PhoneNumber         `protobuf:"bytes,4,rep,name=phones,proto3" json:"phones,omitempty"`
 	LastUpdated *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	Addresses   []*Address             `protobuf:"bytes,6,rep,name=addresses,proto3" json:"addresses,omitempty"`
 }
 
 func (x *Person) Reset() {
@@ -149,6 +254,13 @@ func (x *Person) GetLastUpdated() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Person) GetAddresses() []*Address {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
 type PhoneNumber struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -204,6 +316,94 @@ func (x *PhoneNumber) GetType() PhoneType {
 	return PhoneType_MOBILE
 }
 
+// A postal address of a person.
+type Address struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Street     string       `protobuf:"bytes,1,opt,name=street,proto3" json:"street,omitempty"`
+	City       string       `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	Region     string       `protobuf:"bytes,3,opt,name=region,proto3" json:"region,omitempty"` // State, province or similar.
+	PostalCode string       `protobuf:"bytes,4,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Country    string       `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	Type       Address_Type `protobuf:"varint,6,opt,name=type,proto3,enum=personguide.Address_Type" json:"type,omitempty"`
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Address) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *Address) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Address) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Address) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Address) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Address) GetType() Address_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Address_OTHER
+}
+
 // Our address book file is just one of these.
 type AddressBook struct {
 	state         protoimpl.MessageState
@@ -216,7 +416,7 @@ type AddressBook struct {
 func (x *AddressBook) Reset() {
 	*x = AddressBook{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_person_guide_proto_msgTypes[2]
+		mi := &file_person_guide_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -229,7 +429,7 @@ func (x *AddressBook) String() string {
 func (*AddressBook) ProtoMessage() {}
 
 func (x *AddressBook) ProtoReflect() protoreflect.Message {
-	mi := &file_person_guide_proto_msgTypes[2]
+	mi := &file_person_guide_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -242,7 +442,7 @@ func (x *AddressBook) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddressBook.ProtoReflect.Descriptor instead.
 func (*AddressBook) Descriptor() ([]byte, []int) {
-	return file_person_guide_proto_rawDescGZIP(), []int{2}
+	return file_person_guide_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *AddressBook) GetPeople() []*Person {
@@ -252,18 +452,28 @@ func (x *AddressBook) GetPeople() []*Person {
 	return nil
 }
 
+// Selects the persons having at least one address matching all the non-empty
+// fields. An empty Adress matches every person.
 type Adress struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Describes the query, it isn't used for matching.
+	// City and postal code must be equal to the ones of the address, ignoring
+	// case and spaces in postal codes, or a prefix of them if prefix is set.
+	City       string `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	PostalCode string `protobuf:"bytes,3,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Prefix     bool   `protobuf:"varint,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Region and country must be equal to the ones of the address, ignoring case.
+	Region  string `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	Country string `protobuf:"bytes,6,opt,name=country,proto3" json:"country,omitempty"`
 }
 
 func (x *Adress) Reset() {
 	*x = Adress{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_person_guide_proto_msgTypes[3]
+		mi := &file_person_guide_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -276,7 +486,7 @@ func (x *Adress) String() string {
 func (*Adress) ProtoMessage() {}
 
 func (x *Adress) ProtoReflect() protoreflect.Message {
-	mi := &file_person_guide_proto_msgTypes[3]
+	mi := &file_person_guide_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -289,7 +499,7 @@ func (x *Adress) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Adress.ProtoReflect.Descriptor instead.
 func (*Adress) Descriptor() ([]byte, []int) {
-	return file_person_guide_proto_rawDescGZIP(), []int{3}
+	return file_person_guide_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Adress) GetName() string {
@@ -299,139 +509,815 @@ func (x *Adress) GetName() string {
 	return ""
 }
 
-var File_person_guide_proto protoreflect.FileDescriptor
+func (x *Adress) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
 
-var file_person_guide_proto_rawDesc = []byte{
-	0x0a, 0x12, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x5f, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
-	0x65, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0xb3, 0x01, 0x0a, 0x06, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69,
-	0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x30, 0x0a, 0x06, 0x70, 0x68, 0x6f, 0x6e, 0x65,
-	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e,
-	0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65,
-	0x72, 0x52, 0x06, 0x70, 0x68, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x3d, 0x0a, 0x0c, 0x6c, 0x61, 0x73,
-	0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x6c, 0x61, 0x73,
-	0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x22, 0x51, 0x0a, 0x0b, 0x50, 0x68, 0x6f, 0x6e,
-	0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12,
-	0x2a, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e,
-	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e,
-	0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x3a, 0x0a, 0x0b, 0x41,
-	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x6f, 0x6f, 0x6b, 0x12, 0x2b, 0x0a, 0x06, 0x70, 0x65,
-	0x6f, 0x70, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x65, 0x72,
-	0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52,
-	0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x22, 0x1c, 0x0a, 0x06, 0x41, 0x64, 0x72, 0x65, 0x73,
-	0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x2a, 0x2b, 0x0a, 0x09, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x54, 0x79,
-	0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x4f, 0x42, 0x49, 0x4c, 0x45, 0x10, 0x00, 0x12, 0x08,
-	0x0a, 0x04, 0x48, 0x4f, 0x4d, 0x45, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x4f, 0x52, 0x4b,
-	0x10, 0x02, 0x32, 0x8f, 0x02, 0x0a, 0x0b, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x47, 0x75, 0x69,
-	0x64, 0x65, 0x12, 0x3b, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x12, 0x13,
-	0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72,
-	0x73, 0x6f, 0x6e, 0x1a, 0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
-	0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x00, 0x12,
-	0x3b, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x13,
-	0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x41, 0x64, 0x72,
-	0x65, 0x73, 0x73, 0x1a, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
-	0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x22, 0x00, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0d,
-	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x13, 0x2e,
-	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73,
-	0x6f, 0x6e, 0x1a, 0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65,
-	0x2e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x6f, 0x6f, 0x6b, 0x22, 0x00, 0x28, 0x01,
-	0x12, 0x42, 0x0a, 0x0b, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x73, 0x12,
-	0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65,
-	0x72, 0x73, 0x6f, 0x6e, 0x1a, 0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69,
-	0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x00,
-	0x28, 0x01, 0x30, 0x01, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x6a, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x69, 0x73, 0x2f, 0x67, 0x6f, 0x2d, 0x67,
-	0x72, 0x70, 0x63, 0x2d, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x2f, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *Adress) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
 }
 
-var (
-	file_person_guide_proto_rawDescOnce sync.Once
-	file_person_guide_proto_rawDescData = file_person_guide_proto_rawDesc
-)
+func (x *Adress) GetPrefix() bool {
+	if x != nil {
+		return x.Prefix
+	}
+	return false
+}
 
-func file_person_guide_proto_rawDescGZIP() []byte {
-	file_person_guide_proto_rawDescOnce.Do(func() {
-		file_person_guide_proto_rawDescData = protoimpl.X.CompressGZIP(file_person_guide_proto_rawDescData)
-	})
-	return file_person_guide_proto_rawDescData
+func (x *Adress) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
 }
 
-var file_person_guide_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_person_guide_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_person_guide_proto_goTypes = []interface{}{
-	(PhoneType)(0),                // 0: personguide.PhoneType
-	(*Person)(nil),                // 1: personguide.Person
-	(*PhoneNumber)(nil),           // 2: personguide.PhoneNumber
-	(*AddressBook)(nil),           // 3: personguide.AddressBook
-	(*Adress)(nil),                // 4: personguide.Adress
-	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+func (x *Adress) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
 }
-var file_person_guide_proto_depIdxs = []int32{
-	2, // 0: personguide.Person.phones:type_name -> personguide.PhoneNumber
-	5, // 1: personguide.Person.last_updated:type_name -> google.protobuf.Timestamp
-	0, // 2: personguide.PhoneNumber.type:type_name -> personguide.PhoneType
-	1, // 3: personguide.AddressBook.people:type_name -> personguide.Person
-	1, // 4: personguide.PersonGuide.GetPhone:input_type -> personguide.Person
-	4, // 5: personguide.PersonGuide.ListPersons:input_type -> personguide.Adress
-	1, // 6: personguide.PersonGuide.RecordPersons:input_type -> personguide.Person
-	1, // 7: personguide.PersonGuide.RoutePhones:input_type -> personguide.Person
-	2, // 8: personguide.PersonGuide.GetPhone:output_type -> personguide.PhoneNumber
-	1, // 9: personguide.PersonGuide.ListPersons:output_type -> personguide.Person
-	3, // 10: personguide.PersonGuide.RecordPersons:output_type -> personguide.AddressBook
-	2, // 11: personguide.PersonGuide.RoutePhones:output_type -> personguide.PhoneNumber
-	8, // [8:12] is the sub-list for method output_type
-	4, // [4:8] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+
+type GetPhoneRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The person is found by id, or by email if the id is 0.
+	Id    int32  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Email string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	// The type of the phone to return. The first phone of the person is
+	// returned if it isn't set.
+	Type *PhoneType `protobuf:"varint,6,opt,name=type,proto3,enum=personguide.PhoneType,oneof" json:"type,omitempty"`
 }
 
-func init() { file_person_guide_proto_init() }
-func file_person_guide_proto_init() {
-	if File_person_guide_proto != nil {
-		return
+func (x *GetPhoneRequest) Reset() {
+	*x = GetPhoneRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_person_guide_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Person); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *GetPhoneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPhoneRequest) ProtoMessage() {}
+
+func (x *GetPhoneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_person_guide_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PhoneNumber); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPhoneRequest.ProtoReflect.Descriptor instead.
+func (*GetPhoneRequest) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPhoneRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetPhoneRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *GetPhoneRequest) GetType() PhoneType {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return PhoneType_MOBILE
+}
+
+type GetPersonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetPersonRequest) Reset() {
+	*x = GetPersonRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonRequest) ProtoMessage() {}
+
+func (x *GetPersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_person_guide_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AddressBook); i {
-			case 0:
-				return &v.state
-			case 1:
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPersonRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreatePersonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Person *Person `protobuf:"bytes,1,opt,name=person,proto3" json:"person,omitempty"`
+}
+
+func (x *CreatePersonRequest) Reset() {
+	*x = CreatePersonRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreatePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePersonRequest) ProtoMessage() {}
+
+func (x *CreatePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePersonRequest.ProtoReflect.Descriptor instead.
+func (*CreatePersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreatePersonRequest) GetPerson() *Person {
+	if x != nil {
+		return x.Person
+	}
+	return nil
+}
+
+type UpdatePersonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The person to update, found by its id.
+	Person *Person `protobuf:"bytes,1,opt,name=person,proto3" json:"person,omitempty"`
+	// The fields of the person to update. All fields except the id are
+	// replaced if the mask is empty.
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+}
+
+func (x *UpdatePersonRequest) Reset() {
+	*x = UpdatePersonRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdatePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePersonRequest) ProtoMessage() {}
+
+func (x *UpdatePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePersonRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdatePersonRequest) GetPerson() *Person {
+	if x != nil {
+		return x.Person
+	}
+	return nil
+}
+
+func (x *UpdatePersonRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeletePersonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeletePersonRequest) Reset() {
+	*x = DeletePersonRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeletePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePersonRequest) ProtoMessage() {}
+
+func (x *DeletePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePersonRequest.ProtoReflect.Descriptor instead.
+func (*DeletePersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeletePersonRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type IngestResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Response:
+	//	*IngestResponse_Result
+	//	*IngestResponse_Summary
+	Response isIngestResponse_Response `protobuf_oneof:"response"`
+}
+
+func (x *IngestResponse) Reset() {
+	*x = IngestResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestResponse) ProtoMessage() {}
+
+func (x *IngestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestResponse.ProtoReflect.Descriptor instead.
+func (*IngestResponse) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{10}
+}
+
+func (m *IngestResponse) GetResponse() isIngestResponse_Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (x *IngestResponse) GetResult() *PersonResult {
+	if x, ok := x.GetResponse().(*IngestResponse_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *IngestResponse) GetSummary() *IngestSummary {
+	if x, ok := x.GetResponse().(*IngestResponse_Summary); ok {
+		return x.Summary
+	}
+	return nil
+}
+
+type isIngestResponse_Response interface {
+	isIngestResponse_Response()
+}
+
+type IngestResponse_Result struct {
+	Result *PersonResult `protobuf:"bytes,1,opt,name=result,proto3,oneof"`
+}
+
+type IngestResponse_Summary struct {
+	Summary *IngestSummary `protobuf:"bytes,2,opt,name=summary,proto3,oneof"`
+}
+
+func (*IngestResponse_Result) isIngestResponse_Response() {}
+
+func (*IngestResponse_Summary) isIngestResponse_Response() {}
+
+type PersonResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Position of the person in the request stream, starting at 0.
+	Index   int64                `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id      int32                `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Outcome PersonResult_Outcome `protobuf:"varint,3,opt,name=outcome,proto3,enum=personguide.PersonResult_Outcome" json:"outcome,omitempty"`
+	// Why the person was rejected. Only set when the outcome is REJECTED.
+	Status *status.Status `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *PersonResult) Reset() {
+	*x = PersonResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PersonResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonResult) ProtoMessage() {}
+
+func (x *PersonResult) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonResult.ProtoReflect.Descriptor instead.
+func (*PersonResult) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PersonResult) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PersonResult) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PersonResult) GetOutcome() PersonResult_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return PersonResult_OUTCOME_UNSPECIFIED
+}
+
+func (x *PersonResult) GetStatus() *status.Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type IngestSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Received int64 `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	Accepted int64 `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Updated  int64 `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	Rejected int64 `protobuf:"varint,4,opt,name=rejected,proto3" json:"rejected,omitempty"`
+}
+
+func (x *IngestSummary) Reset() {
+	*x = IngestSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_person_guide_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngestSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestSummary) ProtoMessage() {}
+
+func (x *IngestSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_person_guide_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestSummary.ProtoReflect.Descriptor instead.
+func (*IngestSummary) Descriptor() ([]byte, []int) {
+	return file_person_guide_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *IngestSummary) GetReceived() int64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetAccepted() int64 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetUpdated() int64 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+var File_person_guide_proto protoreflect.FileDescriptor
+
+var file_person_guide_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x5f, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
+	0x65, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe7, 0x01, 0x0a, 0x06, 0x50,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61,
+	0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12,
+	0x30, 0x0a, 0x06, 0x70, 0x68, 0x6f, 0x6e, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68,
+	0x6f, 0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x52, 0x06, 0x70, 0x68, 0x6f, 0x6e, 0x65,
+	0x73, 0x12, 0x3d, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x12, 0x32, 0x0a, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
+	0x65, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x65, 0x73, 0x22, 0x51, 0x0a, 0x0b, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0xde, 0x01, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x65, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63,
+	0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x69, 0x74, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x6f, 0x73, 0x74, 0x61,
+	0x6c, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6f,
+	0x73, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x2d, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x19, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x22, 0x25, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x4f, 0x54, 0x48,
+	0x45, 0x52, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x4f, 0x4d, 0x45, 0x10, 0x01, 0x12, 0x08,
+	0x0a, 0x04, 0x57, 0x4f, 0x52, 0x4b, 0x10, 0x02, 0x22, 0x3a, 0x0a, 0x0b, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x42, 0x6f, 0x6f, 0x6b, 0x12, 0x2b, 0x0a, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e,
+	0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x70, 0x65,
+	0x6f, 0x70, 0x6c, 0x65, 0x22, 0x9b, 0x01, 0x0a, 0x06, 0x41, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x63, 0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x6f, 0x73, 0x74, 0x61,
+	0x6c, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6f,
+	0x73, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x22, 0x99, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x2f, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x48, 0x00, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a,
+	0x05, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x04,
+	0x10, 0x06, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x52, 0x06, 0x70, 0x68, 0x6f, 0x6e, 0x65, 0x73,
+	0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x22, 0x22,
+	0x0a, 0x10, 0x47, 0x65, 0x74, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x69, 0x64, 0x22, 0x42, 0x0a, 0x13, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x06, 0x70, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x06,
+	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x22, 0x7f, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a,
+	0x06, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x52, 0x06, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x0a, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b, 0x22, 0x25, 0x0a, 0x13, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x89,
+	0x01, 0x0a, 0x0e, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x33, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e,
+	0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x48, 0x00, 0x52, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x36, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e,
+	0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x53, 0x75, 0x6d, 0x6d,
+	0x61, 0x72, 0x79, 0x48, 0x00, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x42, 0x0a,
+	0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xea, 0x01, 0x0a, 0x0c, 0x50,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x3b, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x21, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65,
+	0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x4f, 0x75,
+	0x74, 0x63, 0x6f, 0x6d, 0x65, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x12, 0x2a,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x4b, 0x0a, 0x07, 0x4f, 0x75,
+	0x74, 0x63, 0x6f, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x13, 0x4f, 0x55, 0x54, 0x43, 0x4f, 0x4d, 0x45,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0c,
+	0x0a, 0x08, 0x41, 0x43, 0x43, 0x45, 0x50, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07,
+	0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x44, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x45, 0x4a,
+	0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x03, 0x22, 0x7d, 0x0a, 0x0d, 0x49, 0x6e, 0x67, 0x65, 0x73,
+	0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x65,
+	0x69, 0x76, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x63, 0x65,
+	0x69, 0x76, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64,
+	0x12, 0x18, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x2a, 0x2b, 0x0a, 0x09, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x4f, 0x42, 0x49, 0x4c, 0x45, 0x10, 0x00, 0x12,
+	0x08, 0x0a, 0x04, 0x48, 0x4f, 0x4d, 0x45, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x4f, 0x52,
+	0x4b, 0x10, 0x02, 0x32, 0xc7, 0x05, 0x0a, 0x0b, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x47, 0x75,
+	0x69, 0x64, 0x65, 0x12, 0x44, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x12,
+	0x1c, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x47, 0x65,
+	0x74, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e,
+	0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x00, 0x12, 0x3b, 0x0a, 0x0b, 0x4c, 0x69, 0x73,
+	0x74, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x41, 0x64, 0x72, 0x65, 0x73, 0x73, 0x1a, 0x13, 0x2e,
+	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x22, 0x00, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e,
+	0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x1a, 0x18, 0x2e, 0x70,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x42, 0x6f, 0x6f, 0x6b, 0x22, 0x00, 0x28, 0x01, 0x12, 0x42, 0x0a, 0x0b, 0x52, 0x6f,
+	0x75, 0x74, 0x65, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x1a, 0x18,
+	0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f,
+	0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x41,
+	0x0a, 0x09, 0x47, 0x65, 0x74, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12, 0x1d, 0x2e, 0x70, 0x65,
+	0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x22,
+	0x00, 0x12, 0x47, 0x0a, 0x0c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x12, 0x20, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
+	0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x22, 0x00, 0x12, 0x47, 0x0a, 0x0c, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12, 0x20, 0x2e, 0x70, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x22, 0x00, 0x12, 0x4a, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x12, 0x20, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64,
+	0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12,
+	0x47, 0x0a, 0x0d, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x73,
+	0x12, 0x13, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x1a, 0x1b, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75,
+	0x69, 0x64, 0x65, 0x2e, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x43, 0x0a, 0x0b, 0x46, 0x69, 0x6e, 0x64,
+	0x42, 0x79, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x12, 0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e,
+	0x67, 0x75, 0x69, 0x64, 0x65, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x1a, 0x18, 0x2e, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x2e,
+	0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x6f, 0x6f, 0x6b, 0x22, 0x00, 0x42, 0x37, 0x5a,
+	0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x61, 0x63, 0x6b,
+	0x67, 0x72, 0x69, 0x73, 0x2f, 0x67, 0x6f, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x2d, 0x63, 0x6f, 0x6d,
+	0x6d, 0x75, 0x6e, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x67, 0x75, 0x69, 0x64, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_person_guide_proto_rawDescOnce sync.Once
+	file_person_guide_proto_rawDescData = file_person_guide_proto_rawDesc
+)
+
+func file_person_guide_proto_rawDescGZIP() []byte {
+	file_person_guide_proto_rawDescOnce.Do(func() {
+		file_person_guide_proto_rawDescData = protoimpl.X.CompressGZIP(file_person_guide_proto_rawDescData)
+	})
+	return file_person_guide_proto_rawDescData
+}
+
+var file_person_guide_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_person_guide_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_person_guide_proto_goTypes = []interface{}{
+	(PhoneType)(0),                // 0: personguide.PhoneType
+	(Address_Type)(0),             // 1: personguide.Address.Type
+	(PersonResult_Outcome)(0),     // 2: personguide.PersonResult.Outcome
+	(*Person)(nil),                // 3: personguide.Person
+	(*PhoneNumber)(nil),           // 4: personguide.PhoneNumber
+	(*Address)(nil),               // 5: personguide.Address
+	(*AddressBook)(nil),           // 6: personguide.AddressBook
+	(*Adress)(nil),                // 7: personguide.Adress
+	(*GetPhoneRequest)(nil),       // 8: personguide.GetPhoneRequest
+	(*GetPersonRequest)(nil),      // 9: personguide.GetPersonRequest
+	(*CreatePersonRequest)(nil),   // 10: personguide.CreatePersonRequest
+	(*UpdatePersonRequest)(nil),   // 11: personguide.UpdatePersonRequest
+	(*DeletePersonRequest)(nil),   // 12: personguide.DeletePersonRequest
+	(*IngestResponse)(nil),        // 13: personguide.IngestResponse
+	(*PersonResult)(nil),          // 14: personguide.PersonResult
+	(*IngestSummary)(nil),         // 15: personguide.IngestSummary
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 17: google.protobuf.FieldMask
+	(*status.Status)(nil),         // 18: google.rpc.Status
+	(*emptypb.Empty)(nil),         // 19: google.protobuf.Empty
+}
+var file_person_guide_proto_depIdxs = []int32{
+	4,  // 0: personguide.Person.phones:type_name -> personguide.PhoneNumber
+	16, // 1: personguide.Person.last_updated:type_name -> google.protobuf.Timestamp
+	5,  // 2: personguide.Person.addresses:type_name -> personguide.Address
+	0,  // 3: personguide.PhoneNumber.type:type_name -> personguide.PhoneType
+	1,  // 4: personguide.Address.type:type_name -> personguide.Address.Type
+	3,  // 5: personguide.AddressBook.people:type_name -> personguide.Person
+	0,  // 6: personguide.GetPhoneRequest.type:type_name -> personguide.PhoneType
+	3,  // 7: personguide.CreatePersonRequest.person:type_name -> personguide.Person
+	3,  // 8: personguide.UpdatePersonRequest.person:type_name -> personguide.Person
+	17, // 9: personguide.UpdatePersonRequest.update_mask:type_name -> google.protobuf.FieldMask
+	14, // 10: personguide.IngestResponse.result:type_name -> personguide.PersonResult
+	15, // 11: personguide.IngestResponse.summary:type_name -> personguide.IngestSummary
+	2,  // 12: personguide.PersonResult.outcome:type_name -> personguide.PersonResult.Outcome
+	18, // 13: personguide.PersonResult.status:type_name -> google.rpc.Status
+	8,  // 14: personguide.PersonGuide.GetPhone:input_type -> personguide.GetPhoneRequest
+	7,  // 15: personguide.PersonGuide.ListPersons:input_type -> personguide.Adress
+	3,  // 16: personguide.PersonGuide.RecordPersons:input_type -> personguide.Person
+	3,  // 17: personguide.PersonGuide.RoutePhones:input_type -> personguide.Person
+	9,  // 18: personguide.PersonGuide.GetPerson:input_type -> personguide.GetPersonRequest
+	10, // 19: personguide.PersonGuide.CreatePerson:input_type -> personguide.CreatePersonRequest
+	11, // 20: personguide.PersonGuide.UpdatePerson:input_type -> personguide.UpdatePersonRequest
+	12, // 21: personguide.PersonGuide.DeletePerson:input_type -> personguide.DeletePersonRequest
+	3,  // 22: personguide.PersonGuide.IngestPersons:input_type -> personguide.Person
+	4,  // 23: personguide.PersonGuide.FindByPhone:input_type -> personguide.PhoneNumber
+	4,  // 24: personguide.PersonGuide.GetPhone:output_type -> personguide.PhoneNumber
+	3,  // 25: personguide.PersonGuide.ListPersons:output_type -> personguide.Person
+	6,  // 26: personguide.PersonGuide.RecordPersons:output_type -> personguide.AddressBook
+	4,  // 27: personguide.PersonGuide.RoutePhones:output_type -> personguide.PhoneNumber
+	3,  // 28: personguide.PersonGuide.GetPerson:output_type -> personguide.Person
+	3,  // 29: personguide.PersonGuide.CreatePerson:output_type -> personguide.Person
+	3,  // 30: personguide.PersonGuide.UpdatePerson:output_type -> personguide.Person
+	19, // 31: personguide.PersonGuide.DeletePerson:output_type -> google.protobuf.Empty
+	13, // 32: personguide.PersonGuide.IngestPersons:output_type -> personguide.IngestResponse
+	6,  // 33: personguide.PersonGuide.FindByPhone:output_type -> personguide.AddressBook
+	24, // [24:34] is the sub-list for method output_type
+	14, // [14:24] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_person_guide_proto_init() }
+func file_person_guide_proto_init() {
+	if File_person_guide_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_person_guide_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Person); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PhoneNumber); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Address); i {
+			case 0:
+				return &v.state
+			case 1:
 				return &v.sizeCache
 			case 2:
 				return &v.unknownFields
@@ -440,6 +1326,18 @@ func file_person_guide_proto_init() {
 			}
 		}
 		file_person_guide_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddressBook); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Adress); i {
 			case 0:
 				return &v.state
@@ -451,14 +1349,115 @@ func file_person_guide_proto_init() {
 				return nil
 			}
 		}
+		file_person_guide_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPhoneRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPersonRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePersonRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdatePersonRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeletePersonRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngestResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PersonResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_person_guide_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngestSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_person_guide_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_person_guide_proto_msgTypes[10].OneofWrappers = []interface{}{
+		(*IngestResponse_Result)(nil),
+		(*IngestResponse_Summary)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_person_guide_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   4,
+			NumEnums:      3,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
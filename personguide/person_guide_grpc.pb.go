@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -23,6 +24,12 @@ const (
 	PersonGuide_ListPersons_FullMethodName   = "/personguide.PersonGuide/ListPersons"
 	PersonGuide_RecordPersons_FullMethodName = "/personguide.PersonGuide/RecordPersons"
 	PersonGuide_RoutePhones_FullMethodName   = "/personguide.PersonGuide/RoutePhones"
+	PersonGuide_GetPerson_FullMethodName     = "/personguide.PersonGuide/GetPerson"
+	PersonGuide_CreatePerson_FullMethodName  = "/personguide.PersonGuide/CreatePerson"
+	PersonGuide_UpdatePerson_FullMethodName  = "/personguide.PersonGuide/UpdatePerson"
+	PersonGuide_DeletePerson_FullMethodName  = "/personguide.PersonGuide/DeletePerson"
+	PersonGuide_IngestPersons_FullMethodName = "/personguide.PersonGuide/IngestPersons"
+	PersonGuide_FindByPhone_FullMethodName   = "/personguide.PersonGuide/FindByPhone"
 )
 
 // PersonGuideClient is the client API for PersonGuide service.
@@ -31,28 +38,53 @@ const (
 type PersonGuideClient interface {
 	// A simple RPC.
 	//
-	// Obtains the first phone saved at the given person.
+	// Obtains a PhoneNumber of the requested Person.
 	//
-	// A feature with an empty name is returned if there's no feature at the given
-	// position.
-	GetPhone(ctx context.Context, in *Person, opts ...grpc.CallOption) (*PhoneNumber, error)
+	// A NOT_FOUND error is returned if there's no such person, or the person
+	// has no phone of the requested type.
+	GetPhone(ctx context.Context, in *GetPhoneRequest, opts ...grpc.CallOption) (*PhoneNumber, error)
 	// A server-to-client streaming RPC.
 	//
-	// Obtains the Features available within the given Rectangle.  Results are
+	// Obtains the Persons with an address matching the adress.  Results are
 	// streamed rather than returned at once (e.g. in a response message with a
-	// repeated field), as the rectangle may cover a large area and contain a
-	// huge number of features.
+	// repeated field).
 	ListPersons(ctx context.Context, in *Adress, opts ...grpc.CallOption) (PersonGuide_ListPersonsClient, error)
 	// A client-to-server streaming RPC.
 	//
-	// Accepts a stream of Points on a route being traversed, returning a
-	// RouteSummary when traversal is completed.
+	// Accepts a stream of Persons on a route being traversed, returning a
+	// AddressBook when traversal is completed.
 	RecordPersons(ctx context.Context, opts ...grpc.CallOption) (PersonGuide_RecordPersonsClient, error)
 	// A Bidirectional streaming RPC.
 	//
-	// Accepts a stream of RouteNotes sent while a route is being traversed,
-	// while receiving other RouteNotes (e.g. from other users).
+	// Accepts a stream of Person sent while a route is being traversed,
+	// while receiving Phone Numbers (e.g. from other users).
 	RoutePhones(ctx context.Context, opts ...grpc.CallOption) (PersonGuide_RoutePhonesClient, error)
+	// Obtains the Person with the given id.
+	//
+	// A NOT_FOUND error is returned if there's no person with that id.
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error)
+	// Creates a new Person, returning it as saved.
+	//
+	// The server assigns an id when the person has none, and an ALREADY_EXISTS
+	// error is returned if the id is already used.
+	CreatePerson(ctx context.Context, in *CreatePersonRequest, opts ...grpc.CallOption) (*Person, error)
+	// Updates the fields of an existing Person listed in the update mask,
+	// returning the person as saved.
+	UpdatePerson(ctx context.Context, in *UpdatePersonRequest, opts ...grpc.CallOption) (*Person, error)
+	// Deletes the Person with the given id.
+	DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// A Bidirectional streaming RPC.
+	//
+	// Accepts a stream of Persons to save, answering each one with a
+	// PersonResult as soon as it's processed, so rejected persons can be sent
+	// again once fixed. An IngestSummary is sent when the client closes its side
+	// of the stream.
+	IngestPersons(ctx context.Context, opts ...grpc.CallOption) (PersonGuide_IngestPersonsClient, error)
+	// Obtains the Persons having the given phone number, compared ignoring
+	// everything but its digits. The type of the phone isn't used.
+	//
+	// An empty AddressBook is returned if no person has that number.
+	FindByPhone(ctx context.Context, in *PhoneNumber, opts ...grpc.CallOption) (*AddressBook, error)
 }
 
 type personGuideClient struct {
@@ -63,7 +95,7 @@ func NewPersonGuideClient(cc grpc.ClientConnInterface) PersonGuideClient {
 	return &personGuideClient{cc}
 }
 
-func (c *personGuideClient) GetPhone(ctx context.Context, in *Person, opts ...grpc.CallOption) (*PhoneNumber, error) {
+func (c *personGuideClient) GetPhone(ctx context.Context, in *GetPhoneRequest, opts ...grpc.CallOption) (*PhoneNumber, error) {
 	out := new(PhoneNumber)
 	err := c.cc.Invoke(ctx, PersonGuide_GetPhone_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -169,34 +201,135 @@ func (x *personGuideRoutePhonesClient) Recv() (*PhoneNumber, error) {
 	return m, nil
 }
 
+func (c *personGuideClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonGuide_GetPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personGuideClient) CreatePerson(ctx context.Context, in *CreatePersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonGuide_CreatePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personGuideClient) UpdatePerson(ctx context.Context, in *UpdatePersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonGuide_UpdatePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personGuideClient) DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PersonGuide_DeletePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personGuideClient) IngestPersons(ctx context.Context, opts ...grpc.CallOption) (PersonGuide_IngestPersonsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PersonGuide_ServiceDesc.Streams[3], PersonGuide_IngestPersons_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &personGuideIngestPersonsClient{stream}
+	return x, nil
+}
+
+type PersonGuide_IngestPersonsClient interface {
+	Send(*Person) error
+	Recv() (*IngestResponse, error)
+	grpc.ClientStream
+}
+
+type personGuideIngestPersonsClient struct {
+	grpc.ClientStream
+}
+
+func (x *personGuideIngestPersonsClient) Send(m *Person) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *personGuideIngestPersonsClient) Recv() (*IngestResponse, error) {
+	m := new(IngestResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *personGuideClient) FindByPhone(ctx context.Context, in *PhoneNumber, opts ...grpc.CallOption) (*AddressBook, error) {
+	out := new(AddressBook)
+	err := c.cc.Invoke(ctx, PersonGuide_FindByPhone_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PersonGuideServer is the server API for PersonGuide service.
 // All implementations must embed UnimplementedPersonGuideServer
 // for forward compatibility
 type PersonGuideServer interface {
 	// A simple RPC.
 	//
-	// Obtains the first phone saved at the given person.
+	// Obtains a PhoneNumber of the requested Person.
 	//
-	// A feature with an empty name is returned if there's no feature at the given
-	// position.
-	GetPhone(context.Context, *Person) (*PhoneNumber, error)
+	// A NOT_FOUND error is returned if there's no such person, or the person
+	// has no phone of the requested type.
+	GetPhone(context.Context, *GetPhoneRequest) (*PhoneNumber, error)
 	// A server-to-client streaming RPC.
 	//
-	// Obtains the Features available within the given Rectangle.  Results are
+	// Obtains the Persons with an address matching the adress.  Results are
 	// streamed rather than returned at once (e.g. in a response message with a
-	// repeated field), as the rectangle may cover a large area and contain a
-	// huge number of features.
+	// repeated field).
 	ListPersons(*Adress, PersonGuide_ListPersonsServer) error
 	// A client-to-server streaming RPC.
 	//
-	// Accepts a stream of Points on a route being traversed, returning a
-	// RouteSummary when traversal is completed.
+	// Accepts a stream of Persons on a route being traversed, returning a
+	// AddressBook when traversal is completed.
 	RecordPersons(PersonGuide_RecordPersonsServer) error
 	// A Bidirectional streaming RPC.
 	//
-	// Accepts a stream of RouteNotes sent while a route is being traversed,
-	// while receiving other RouteNotes (e.g. from other users).
+	// Accepts a stream of Person sent while a route is being traversed,
+	// while receiving Phone Numbers (e.g. from other users).
 	RoutePhones(PersonGuide_RoutePhonesServer) error
+	// Obtains the Person with the given id.
+	//
+	// A NOT_FOUND error is returned if there's no person with that id.
+	GetPerson(context.Context, *GetPersonRequest) (*Person, error)
+	// Creates a new Person, returning it as saved.
+	//
+	// The server assigns an id when the person has none, and an ALREADY_EXISTS
+	// error is returned if the id is already used.
+	CreatePerson(context.Context, *CreatePersonRequest) (*Person, error)
+	// Updates the fields of an existing Person listed in the update mask,
+	// returning the person as saved.
+	UpdatePerson(context.Context, *UpdatePersonRequest) (*Person, error)
+	// Deletes the Person with the given id.
+	DeletePerson(context.Context, *DeletePersonRequest) (*emptypb.Empty, error)
+	// A Bidirectional streaming RPC.
+	//
+	// Accepts a stream of Persons to save, answering each one with a
+	// PersonResult as soon as it's processed, so rejected persons can be sent
+	// again once fixed. An IngestSummary is sent when the client closes its side
+	// of the stream.
+	IngestPersons(PersonGuide_IngestPersonsServer) error
+	// Obtains the Persons having the given phone number, compared ignoring
+	// everything but its digits. The type of the phone isn't used.
+	//
+	// An empty AddressBook is returned if no person has that number.
+	FindByPhone(context.Context, *PhoneNumber) (*AddressBook, error)
 	mustEmbedUnimplementedPersonGuideServer()
 }
 
@@ -204,7 +337,7 @@ type PersonGuideServer interface {
 type UnimplementedPersonGuideServer struct {
 }
 
-func (UnimplementedPersonGuideServer) GetPhone(context.Context, *Person) (*PhoneNumber, error) {
+func (UnimplementedPersonGuideServer) GetPhone(context.Context, *GetPhoneRequest) (*PhoneNumber, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPhone not implemented")
 }
 func (UnimplementedPersonGuideServer) ListPersons(*Adress, PersonGuide_ListPersonsServer) error {
@@ -216,6 +349,24 @@ func (UnimplementedPersonGuideServer) RecordPersons(PersonGuide_RecordPersonsSer
 func (UnimplementedPersonGuideServer) RoutePhones(PersonGuide_RoutePhonesServer) error {
 	return status.Errorf(codes.Unimplemented, "method RoutePhones not implemented")
 }
+func (UnimplementedPersonGuideServer) GetPerson(context.Context, *GetPersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedPersonGuideServer) CreatePerson(context.Context, *CreatePersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePerson not implemented")
+}
+func (UnimplementedPersonGuideServer) UpdatePerson(context.Context, *UpdatePersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePerson not implemented")
+}
+func (UnimplementedPersonGuideServer) DeletePerson(context.Context, *DeletePersonRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePerson not implemented")
+}
+func (UnimplementedPersonGuideServer) IngestPersons(PersonGuide_IngestPersonsServer) error {
+	return status.Errorf(codes.Unimplemented, "method IngestPersons not implemented")
+}
+func (UnimplementedPersonGuideServer) FindByPhone(context.Context, *PhoneNumber) (*AddressBook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindByPhone not implemented")
+}
 func (UnimplementedPersonGuideServer) mustEmbedUnimplementedPersonGuideServer() {}
 
 // UnsafePersonGuideServer may be embedded to opt out of forward compatibility for this service.
@@ -230,7 +381,7 @@ func RegisterPersonGuideServer(s grpc.ServiceRegistrar, srv PersonGuideServer) {
 }
 
 func _PersonGuide_GetPhone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Person)
+	in := new(GetPhoneRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -242,7 +393,7 @@ func _PersonGuide_GetPhone_Handler(srv interface{}, ctx context.Context, dec fun
 		FullMethod: PersonGuide_GetPhone_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PersonGuideServer).GetPhone(ctx, req.(*Person))
+		return srv.(PersonGuideServer).GetPhone(ctx, req.(*GetPhoneRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -320,6 +471,122 @@ func (x *personGuideRoutePhonesServer) Recv() (*Person, error) {
 	return m, nil
 }
 
+func _PersonGuide_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonGuideServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonGuide_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonGuideServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonGuide_CreatePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonGuideServer).CreatePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonGuide_CreatePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonGuideServer).CreatePerson(ctx, req.(*CreatePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonGuide_UpdatePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonGuideServer).UpdatePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonGuide_UpdatePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonGuideServer).UpdatePerson(ctx, req.(*UpdatePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonGuide_DeletePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonGuideServer).DeletePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonGuide_DeletePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonGuideServer).DeletePerson(ctx, req.(*DeletePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonGuide_IngestPersons_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PersonGuideServer).IngestPersons(&personGuideIngestPersonsServer{stream})
+}
+
+type PersonGuide_IngestPersonsServer interface {
+	Send(*IngestResponse) error
+	Recv() (*Person, error)
+	grpc.ServerStream
+}
+
+type personGuideIngestPersonsServer struct {
+	grpc.ServerStream
+}
+
+func (x *personGuideIngestPersonsServer) Send(m *IngestResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *personGuideIngestPersonsServer) Recv() (*Person, error) {
+	m := new(Person)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _PersonGuide_FindByPhone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PhoneNumber)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonGuideServer).FindByPhone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonGuide_FindByPhone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonGuideServer).FindByPhone(ctx, req.(*PhoneNumber))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PersonGuide_ServiceDesc is the grpc.ServiceDesc for PersonGuide service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -331,6 +598,26 @@ var PersonGuide_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPhone",
 			Handler:    _PersonGuide_GetPhone_Handler,
 		},
+		{
+			MethodName: "GetPerson",
+			Handler:    _PersonGuide_GetPerson_Handler,
+		},
+		{
+			MethodName: "CreatePerson",
+			Handler:    _PersonGuide_CreatePerson_Handler,
+		},
+		{
+			MethodName: "UpdatePerson",
+			Handler:    _PersonGuide_UpdatePerson_Handler,
+		},
+		{
+			MethodName: "DeletePerson",
+			Handler:    _PersonGuide_DeletePerson_Handler,
+		},
+		{
+			MethodName: "FindByPhone",
+			Handler:    _PersonGuide_FindByPhone_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -349,6 +636,12 @@ var PersonGuide_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "IngestPersons",
+			Handler:       _PersonGuide_IngestPersons_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "person_guide.proto",
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/jackgris/go-grpc-communication/auth"
+)
+
+// authorizer is the check of every call, as given to guide.WithAuth.
+type authorizer = func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// newAuthorizer returns the authorizer of the calls: it takes the identity
+// of the client certificate, requires a bearer token if jwksFile is set, and
+// then checks the policy in policyFile, if set.
+func newAuthorizer(jwksFile, issuer, audience, policyFile string) (authorizer, error) {
+	authorizers := []authorizer{auth.AuthenticatePeer}
+	if jwksFile != "" {
+		tokens, err := auth.NewJWTAuthenticator(jwksFile, issuer, audience)
+		if err != nil {
+			return nil, err
+		}
+		authorizers = append(authorizers, tokens.Authorize)
+	}
+	if policyFile != "" {
+		policy, err := auth.LoadPolicy(policyFile)
+		if err != nil {
+			return nil, err
+		}
+		authorizers = append(authorizers, policy.Authorize)
+	}
+	return auth.Chain(authorizers...), nil
+}
+
+// reflectionInterceptor checks the calls to the reflection service with
+// authorize. It's registered by the reflection package, so unlike the
+// PersonGuide service it can't be given the authorizer.
+func reflectionInterceptor(authorize authorizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+			return handler(srv, ss)
+		}
+		ctx, err := authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackgris/go-grpc-communication/config"
+)
+
+// envPrefix starts the variables overriding the config file.
+const envPrefix = "PERSONGUIDE_SERVER_"
+
+// serverConfig is the configuration of the server, set from the config file,
+// the variables and the flags, in that order.
+type serverConfig struct {
+	TLS                bool
+	MTLS               bool
+	CertFile           string
+	KeyFile            string
+	ClientCAFile       string
+	CRL                string
+	CertReloadInterval time.Duration
+	AuthzPolicyFile    string
+	JWKSFile           string
+	JWTIssuer          string
+	JWTAudience        string
+	JSONDBFile         string
+	Port               int
+	Listen             string
+	UnixSocketMode     string
+	Store              string
+	StoreFile          string
+	LogLevel           string
+	LogFormat          string
+	TraceExporter      string
+	TraceFile          string
+	OTLPEndpoint       string
+	OTLPInsecure       bool
+	ShutdownTimeout    time.Duration
+	ShutdownDrain      time.Duration
+	MetricsAddr        string
+}
+
+// registerFlags defines a flag for every setting of c in fs, with its
+// default value.
+func (c *serverConfig) registerFlags(fs *flag.FlagSet) {
+	fs.String(config.FileFlag, "", "A YAML or TOML file with the settings, keyed by flag name, overridden by "+envPrefix+"<FLAG> variables and flags")
+	fs.BoolVar(&c.TLS, "tls", false, "Connection uses TLS if true, else plain TCP")
+	fs.BoolVar(&c.MTLS, "mtls", false, "Connection uses TLS and requires clients to present a certificate signed by the client CA")
+	fs.StringVar(&c.CertFile, "cert_file", "", "The TLS cert file")
+	fs.StringVar(&c.KeyFile, "key_file", "", "The TLS key file")
+	fs.StringVar(&c.ClientCAFile, "client_ca_file", "", "The file containing the CA root cert used to verify client certs")
+	fs.StringVar(&c.CRL, "crl", "", "A CRL file, or a directory of them, with the revoked client certs, only used with mTLS")
+	fs.DurationVar(&c.CertReloadInterval, "cert_reload_interval", time.Minute, "How often cert files are checked for changes, never if 0")
+	fs.StringVar(&c.AuthzPolicyFile, "authz_policy_file", "", "A json file with the methods each caller may call, everything is allowed if empty")
+	fs.StringVar(&c.JWKSFile, "jwks_file", "", "A JWKS file with the keys that sign bearer tokens, tokens are required if set")
+	fs.StringVar(&c.JWTIssuer, "jwt_issuer", "jwtgen", "The issuer bearer tokens must have")
+	fs.StringVar(&c.JWTAudience, "jwt_audience", "personguide", "The audience bearer tokens must have")
+	fs.StringVar(&c.JSONDBFile, "json_db_file", "", "A json file containing a list of persons, the memory store starts with example persons if empty")
+	fs.IntVar(&c.Port, "port", 50051, "The server port, on localhost if listen is empty")
+	fs.StringVar(&c.Listen, "listen", "", "The addresses to serve on, separated by commas: host:port, :port for every interface, or unix:///path for a Unix socket")
+	fs.StringVar(&c.UnixSocketMode, "unix_socket_mode", "0660", "The permissions of the Unix sockets, in octal")
+	fs.StringVar(&c.Store, "store", "memory", "Where persons are stored: memory, or bolt for an on-disk database")
+	fs.StringVar(&c.StoreFile, "store_file", "persons.db", "The database file used by the bolt store")
+	fs.StringVar(&c.LogLevel, "log_level", "info", "The minimum level of the logs: debug, info, warn or error")
+	fs.StringVar(&c.LogFormat, "log_format", "text", "The format of the logs: text or json")
+	fs.StringVar(&c.TraceExporter, "trace_exporter", "", "Where spans are exported: otlp, file, or none if empty")
+	fs.StringVar(&c.TraceFile, "trace_file", "traces.json", "The file spans are appended to by the file exporter")
+	fs.StringVar(&c.OTLPEndpoint, "otlp_endpoint", "", "The host:port of the OTLP collector, OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317 if empty")
+	fs.BoolVar(&c.OTLPInsecure, "otlp_insecure", false, "Connect to the OTLP collector without TLS")
+	fs.DurationVar(&c.ShutdownTimeout, "shutdown_timeout", 30*time.Second, "How long calls in progress may take to finish on SIGINT or SIGTERM, before being cancelled")
+	fs.DurationVar(&c.ShutdownDrain, "shutdown_drain", 0, "How long to report NOT_SERVING before refusing new calls on SIGINT or SIGTERM")
+	fs.StringVar(&c.MetricsAddr, "metrics_addr", "", "The host:port of the HTTP server exposing Prometheus metrics on /metrics, none if empty")
+}
+
+// validate returns every inconsistent or out of range setting of c.
+func (c *serverConfig) validate() error {
+	var errs []error
+	if c.Port < 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port %d is out of range", c.Port))
+	}
+	for _, addr := range listenAddrs(c.Listen, c.Port) {
+		if path, ok := unixSocketPath(addr); ok {
+			if path == "" {
+				errs = append(errs, fmt.Errorf("listen address %q has no socket path", addr))
+			}
+		} else if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid listen address %q: %w", addr, err))
+		}
+	}
+	if c.Listen != "" && len(listenAddrs(c.Listen, c.Port)) == 0 {
+		errs = append(errs, errors.New("listen has no addresses"))
+	}
+	if _, err := parseFileMode(c.UnixSocketMode); err != nil {
+		errs = append(errs, err)
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, errors.New("cert_file and key_file must be set together"))
+	}
+	if !c.MTLS && (c.ClientCAFile != "" || c.CRL != "") {
+		errs = append(errs, errors.New("client_ca_file and crl are only used with mtls"))
+	}
+	if c.JWKSFile != "" && (c.JWTIssuer == "" || c.JWTAudience == "") {
+		errs = append(errs, errors.New("jwt_issuer and jwt_audience are required by jwks_file"))
+	}
+	switch c.Store {
+	case "memory":
+	case "bolt":
+		if c.StoreFile == "" {
+			errs = append(errs, errors.New("store_file is required by the bolt store"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown store %q, must be memory or bolt", c.Store))
+	}
+	if _, err := newLogger(io.Discard, c.LogLevel, c.LogFormat); err != nil {
+		errs = append(errs, err)
+	}
+	switch c.TraceExporter {
+	case "", "otlp", "file":
+	default:
+		errs = append(errs, fmt.Errorf("unknown trace exporter %q, must be otlp or file", c.TraceExporter))
+	}
+	if c.CertReloadInterval < 0 || c.ShutdownTimeout < 0 || c.ShutdownDrain < 0 {
+		errs = append(errs, errors.New("cert_reload_interval, shutdown_timeout and shutdown_drain can't be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// runConfigCommand runs the command given by the arguments following the
+// flags, of which there is only one: config dump [--format yaml|toml].
+func runConfigCommand(fs *flag.FlagSet, args []string) error {
+	if len(args) < 2 || args[0] != "config" || args[1] != "dump" {
+		return fmt.Errorf("unknown command %q, only config dump is supported", strings.Join(args, " "))
+	}
+	dump := flag.NewFlagSet("config dump", flag.ContinueOnError)
+	format := dump.String("format", "yaml", "The format of the settings: yaml or toml")
+	if err := dump.Parse(args[2:]); err != nil {
+		return err
+	}
+	return config.Dump(os.Stdout, fs, *format)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// loadServerCredentials returns server credentials that present the given
+// certificate, and when it expires. If clientCAFile isn't empty, clients are
+// required to present a certificate signed by the CA in it, and not revoked
+// by the lists in crlPath if it isn't empty either.
+func loadServerCredentials(certFile, keyFile, clientCAFile, crlPath string) (credentials.TransportCredentials, time.Time, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("%s: %w", certFile, err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		ca, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		pool := x509.NewCertPool()
+		var cas []*x509.Certificate
+		for block, rest := pem.Decode(ca); block != nil; block, rest = pem.Decode(rest) {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("%s: %w", clientCAFile, err)
+			}
+			pool.AddCert(cert)
+			cas = append(cas, cert)
+		}
+		if len(cas) == 0 {
+			return nil, time.Time{}, fmt.Errorf("no certificates found in %s", clientCAFile)
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = pool
+
+		if crlPath != "" {
+			crls, err := loadCRLs(crlPath, cas)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			config.VerifyPeerCertificate = crls.verifyPeerCertificate
+		}
+	}
+	return credentials.NewTLS(config), leaf.NotAfter, nil
+}
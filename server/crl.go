@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// crlSet holds the certificate revocation lists of the client CAs, used to
+// reject revoked client certificates during the handshake.
+type crlSet struct {
+	lists []*x509.RevocationList
+}
+
+// loadCRLs reads the revocation lists in path, which is either a file or a
+// directory of files, each with PEM or DER encoded lists. Every list must be
+// signed by one of the CAs.
+func loadCRLs(path string, cas []*x509.Certificate) (*crlSet, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+
+	set := &crlSet{}
+	for _, f := range files {
+		lists, err := readCRLFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		for _, crl := range lists {
+			if err := checkCRLIssuer(crl, cas); err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+			if !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(time.Now()) {
+				slog.Warn("CRL is out of date", "file", f, "next_update", crl.NextUpdate)
+			}
+			set.lists = append(set.lists, crl)
+		}
+	}
+	return set, nil
+}
+
+func readCRLFile(f string) ([]*x509.RevocationList, error) {
+	b, err := os.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(b, []byte("-----BEGIN")) {
+		crl, err := x509.ParseRevocationList(b)
+		if err != nil {
+			return nil, err
+		}
+		return []*x509.RevocationList{crl}, nil
+	}
+	var lists []*x509.RevocationList
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, crl)
+	}
+	return lists, nil
+}
+
+// checkCRLIssuer returns an error unless the list was signed by one of the CAs.
+func checkCRLIssuer(crl *x509.RevocationList, cas []*x509.Certificate) error {
+	for _, ca := range cas {
+		if bytes.Equal(ca.RawSubject, crl.RawIssuer) && crl.CheckSignatureFrom(ca) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("CRL of %q isn't signed by a client CA", crl.Issuer)
+}
+
+// verifyPeerCertificate rejects the client certificates revoked by any of
+// the lists. It is meant to be used as tls.Config.VerifyPeerCertificate, so
+// it's only called with chains already verified against the client CAs.
+func (c *crlSet) verifyPeerCertificate(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	for _, chain := range verifiedChains {
+		for _, cert := range chain {
+			if err := c.check(cert); err != nil {
+				leaf := chain[0]
+				slog.Warn("Rejected client certificate", "subject", leaf.Subject.String(), "error", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// check returns an error if the certificate was revoked by its issuer.
+func (c *crlSet) check(cert *x509.Certificate) error {
+	for _, crl := range c.lists {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+			continue
+		}
+		for _, revoked := range crl.RevokedCertificateEntries {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+				continue
+			}
+			return fmt.Errorf("certificate %q (serial %s) was revoked at %v, reason: %s",
+				cert.Subject, cert.SerialNumber, revoked.RevocationTime, revocationReason(revoked.ReasonCode))
+		}
+	}
+	return nil
+}
+
+// revocationReason returns the name of a CRLReason from RFC 5280.
+func revocationReason(code int) string {
+	reasons := map[int]string{
+		0:  "unspecified",
+		1:  "keyCompromise",
+		2:  "cACompromise",
+		3:  "affiliationChanged",
+		4:  "superseded",
+		5:  "cessationOfOperation",
+		6:  "certificateHold",
+		8:  "removeFromCRL",
+		9:  "privilegeWithdrawn",
+		10: "aACompromise",
+	}
+	if r, ok := reasons[code]; ok {
+		return r
+	}
+	return fmt.Sprintf("unknown (%d)", code)
+}
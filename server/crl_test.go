@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority creating client certificates and
+// revocation lists.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeCRL writes a PEM revocation list revoking the serials, returning its
+// path.
+func (ca *testCA) writeCRL(t *testing.T, dir string, serials ...int64) string {
+	t.Helper()
+	tmpl := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, serial := range serials {
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: time.Now().Add(-time.Minute),
+			ReasonCode:     1,
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ca.cert.Subject.CommonName+".crl")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCRLSetCheck(t *testing.T) {
+	ca, otherCA := newTestCA(t, "ca"), newTestCA(t, "other-ca")
+	dir := t.TempDir()
+	ca.writeCRL(t, dir, 2)
+	otherCA.writeCRL(t, dir, 3)
+	crls, err := loadCRLs(dir, []*x509.Certificate{ca.cert, otherCA.cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate
+		revoked bool
+	}{
+		{"revoked", ca.issue(t, 2), true},
+		{"not revoked", ca.issue(t, 3), false},
+		{"revoked by the other CA", otherCA.issue(t, 3), true},
+		{"serial revoked by another issuer", otherCA.issue(t, 2), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := crls.check(tt.cert)
+			if revoked := err != nil; revoked != tt.revoked {
+				t.Errorf("check() = %v, want revoked %v", err, tt.revoked)
+			}
+		})
+	}
+}
+
+func TestLoadCRLsRejectsUnknownIssuer(t *testing.T) {
+	ca, otherCA := newTestCA(t, "ca"), newTestCA(t, "other-ca")
+	path := otherCA.writeCRL(t, t.TempDir(), 2)
+	if _, err := loadCRLs(path, []*x509.Certificate{ca.cert}); err == nil {
+		t.Error("loadCRLs succeeded with a CRL of another CA, want an error")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// readiness reports through the standard health service whether the
+// PersonGuide service can be used. The service itself rejects the calls made
+// before its persons are loaded.
+//
+// It implements the health service, ending the Watch calls once the server
+// is stopping, as they would otherwise keep it from stopping gracefully.
+type readiness struct {
+	health   *health.Server
+	stopping chan struct{}
+}
+
+// newReadiness returns a readiness reporting everything as NOT_SERVING.
+func newReadiness() *readiness {
+	r := &readiness{health: health.NewServer(), stopping: make(chan struct{})}
+	r.set(false)
+	return r
+}
+
+// set changes the status reported for the server as a whole and for the
+// PersonGuide service.
+func (r *readiness) set(ready bool) {
+	st := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		st = healthpb.HealthCheckResponse_SERVING
+	}
+	r.health.SetServingStatus("", st)
+	r.health.SetServingStatus(pb.PersonGuide_ServiceDesc.ServiceName, st)
+}
+
+// shutdown reports NOT_SERVING from now on, ignoring any later set, so load
+// balancers stop sending calls. The calls that still arrive are served.
+func (r *readiness) shutdown() {
+	r.health.Shutdown()
+}
+
+// stopWatches ends the Watch calls in progress with Unavailable, and the
+// ones made from now on right away. It must be called once, after shutdown.
+func (r *readiness) stopWatches() {
+	close(r.stopping)
+}
+
+func (r *readiness) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return r.health.Check(ctx, req)
+}
+
+func (r *readiness) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err := r.health.Watch(req, &watchStream{Health_WatchServer: stream, ctx: ctx})
+	select {
+	case <-r.stopping:
+		return status.Error(codes.Unavailable, "the server is stopping")
+	default:
+		return err
+	}
+}
+
+// watchStream is a Watch stream whose context is cancelled when the server
+// is stopping.
+type watchStream struct {
+	healthpb.Health_WatchServer
+	ctx context.Context
+}
+
+func (w *watchStream) Context() context.Context {
+	return w.ctx
+}
+
+// isHealthCheck reports whether the method belongs to the health service.
+func isHealthCheck(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+func dialHealth(t *testing.T, ready *readiness) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, ready)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestReadiness(t *testing.T) {
+	ready := newReadiness()
+	client := dialHealth(t, ready)
+
+	check := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for _, service := range []string{"", pb.PersonGuide_ServiceDesc.ServiceName} {
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != want {
+				t.Errorf("status of %q = %v, want %v", service, resp.Status, want)
+			}
+		}
+	}
+	check(healthpb.HealthCheckResponse_NOT_SERVING)
+	ready.set(true)
+	check(healthpb.HealthCheckResponse_SERVING)
+	ready.shutdown()
+	check(healthpb.HealthCheckResponse_NOT_SERVING)
+	ready.set(true)
+	check(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestReadinessStopWatches(t *testing.T) {
+	ready := newReadiness()
+	ready.set(true)
+	client := dialHealth(t, ready)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("watched %v, want SERVING", resp.Status)
+	}
+
+	ready.shutdown()
+	if resp, err := stream.Recv(); err != nil || resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("watched %v, %v after shutdown, want NOT_SERVING", resp, err)
+	}
+	ready.stopWatches()
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v after stopWatches, want Unavailable", err)
+	}
+
+	// Watch calls made after stopWatches end right away, at most after
+	// sending the current status.
+	stream, err = client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v from a Watch made after stopWatches, want Unavailable", err)
+	}
+}
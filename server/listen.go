@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenAddrs returns the addresses in the comma separated list, or
+// localhost:port if it's empty.
+func listenAddrs(list string, port int) []string {
+	if list == "" {
+		return []string{fmt.Sprintf("localhost:%d", port)}
+	}
+	var addrs []string
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// unixSocketPath returns the path of a unix:///path or unix:path address.
+func unixSocketPath(addr string) (string, bool) {
+	if path, ok := strings.CutPrefix(addr, "unix://"); ok {
+		return path, true
+	}
+	return strings.CutPrefix(addr, "unix:")
+}
+
+// parseFileMode parses the permissions of a file in octal, like 0660.
+func parseFileMode(mode string) (fs.FileMode, error) {
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q, must be octal like 0660", mode)
+	}
+	return fs.FileMode(m), nil
+}
+
+// listen listens on every address, either host:port for TCP or a Unix socket
+// created with the given permissions. A socket left by a previous run is
+// replaced.
+func listen(addrs []string, socketMode fs.FileMode) ([]net.Listener, error) {
+	var lis []net.Listener
+	for _, addr := range addrs {
+		l, err := listenOne(addr, socketMode)
+		if err != nil {
+			for _, l := range lis {
+				l.Close()
+			}
+			return nil, fmt.Errorf("%s: %w", addr, err)
+		}
+		lis = append(lis, l)
+	}
+	return lis, nil
+}
+
+func listenOne(addr string, socketMode fs.FileMode) (net.Listener, error) {
+	path, ok := unixSocketPath(addr)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, errors.New("exists and isn't a socket")
+		}
+		if c, err := net.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, errors.New("in use by another process")
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
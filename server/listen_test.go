@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenAddrs(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"", []string{"localhost:50051"}},
+		{"0.0.0.0:8080", []string{"0.0.0.0:8080"}},
+		{" :8080 , unix:///run/guide.sock,", []string{":8080", "unix:///run/guide.sock"}},
+	}
+	for _, tt := range tests {
+		if got := listenAddrs(tt.list, 50051); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("listenAddrs(%q) = %q, want %q", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestUnixSocketPath(t *testing.T) {
+	tests := []struct {
+		addr string
+		path string
+		ok   bool
+	}{
+		{"unix:///run/guide.sock", "/run/guide.sock", true},
+		{"unix:guide.sock", "guide.sock", true},
+		{"localhost:50051", "", false},
+		{"[::1]:50051", "", false},
+	}
+	for _, tt := range tests {
+		path, ok := unixSocketPath(tt.addr)
+		if ok != tt.ok || ok && path != tt.path {
+			t.Errorf("unixSocketPath(%q) = %q, %v, want %q, %v", tt.addr, path, ok, tt.path, tt.ok)
+		}
+	}
+}
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    fs.FileMode
+		wantErr bool
+	}{
+		{"0660", 0o660, false},
+		{"600", 0o600, false},
+		{"0777", 0o777, false},
+		{"1777", 0, true},
+		{"0680", 0, true},
+		{"rw-rw----", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseFileMode(tt.mode)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseFileMode(%q) = %v, %v, want %v, error %v", tt.mode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guide.sock")
+	// A socket left by a process that didn't remove it.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	lis, err := listen([]string{"unix://" + path, "localhost:0"}, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, l := range lis {
+			l.Close()
+		}
+	}()
+	if len(lis) != 2 {
+		t.Fatalf("got %d listeners, want 2", len(lis))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("socket mode = %v, want %v", mode, fs.FileMode(0o600))
+	}
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("dialing the new socket: %v", err)
+	}
+	c.Close()
+
+	// The socket is now in use, so listening again fails.
+	if _, err := listen([]string{"unix://" + path}, 0o600); err == nil {
+		t.Error("listening on a socket in use succeeded")
+	}
+}
+
+func TestListenRefusesToReplaceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guide.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listen([]string{"localhost:0", "unix:" + path}, 0o600); err == nil {
+		t.Fatal("listening on a regular file succeeded")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("file changed to %q, %v", data, err)
+	}
+}
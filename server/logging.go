@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/jackgris/go-grpc-communication/auth"
+)
+
+// newLogger returns a logger writing records of at least the given level,
+// debug, info, warn or error, to w as text or json.
+func newLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q, must be one of debug, info, warn or error", level)
+	}
+	opts := &slog.HandlerOptions{Level: l}
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be text or json", format)
+	}
+}
+
+// logRequest logs a finished call, made by the caller in holder, or by the
+// peer if no authorizer authenticated it. Successful health checks are only logged
+// at debug level, as load balancers make lots of them.
+func logRequest(ctx context.Context, holder *auth.IdentityHolder, fullMethod string, start time.Time, err error, attrs ...slog.Attr) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+		if isHealthCheck(fullMethod) {
+			level = slog.LevelDebug
+		}
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append([]slog.Attr{
+		slog.String("method", fullMethod),
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+	}, attrs...)
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	id, ok := holder.Identity()
+	if !ok {
+		id, ok = auth.PeerIdentity(ctx)
+	}
+	if ok {
+		attrs = append(attrs, slog.String("caller", strings.Join(id.Principals(), ",")))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	slog.LogAttrs(ctx, level, "Finished call", attrs...)
+}
+
+// loggingUnaryInterceptor logs every unary call, once it's finished.
+func loggingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, holder := auth.WithIdentityHolder(ctx)
+	resp, err := handler(ctx, req)
+	logRequest(ctx, holder, info.FullMethod, start, err)
+	return resp, err
+}
+
+// loggingStreamInterceptor logs every streaming call, once it's finished,
+// with the number of messages received and sent.
+func loggingStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, holder := auth.WithIdentityHolder(ss.Context())
+	cs := &countingStream{ServerStream: &wrappedStream{ServerStream: ss, ctx: ctx}}
+	err := handler(srv, cs)
+	logRequest(ctx, holder, info.FullMethod, start, err,
+		slog.Int("received", cs.received), slog.Int("sent", cs.sent))
+	return err
+}
+
+// countingStream counts the messages received and sent on a stream.
+type countingStream struct {
+	grpc.ServerStream
+	received, sent int
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+	return err
+}
+
+// wrappedStream is a grpc.ServerStream with a different context.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
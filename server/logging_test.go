@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jackgris/go-grpc-communication/auth"
+)
+
+// captureLogs makes the default logger write json records of every level to
+// the returned buffer until the test ends.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// lastRecord returns the last record written to buf.
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var record map[string]interface{}
+	if err := json.Unmarshal(lines[len(lines)-1], &record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+func TestLogRequestLevel(t *testing.T) {
+	tests := []struct {
+		method string
+		err    error
+		level  string
+	}{
+		{"/personguide.PersonGuide/GetPerson", nil, "INFO"},
+		{"/grpc.health.v1.Health/Check", nil, "DEBUG"},
+		{"/grpc.health.v1.Health/Check", status.Error(codes.Unavailable, "stopping"), "WARN"},
+		{"/personguide.PersonGuide/GetPerson", status.Error(codes.NotFound, "no person"), "WARN"},
+		{"/personguide.PersonGuide/GetPerson", status.Error(codes.InvalidArgument, "bad id"), "WARN"},
+		{"/personguide.PersonGuide/GetPerson", status.Error(codes.Internal, "broken"), "ERROR"},
+		{"/personguide.PersonGuide/GetPerson", status.Error(codes.Unimplemented, "missing"), "ERROR"},
+		{"/personguide.PersonGuide/GetPerson", io.ErrUnexpectedEOF, "ERROR"},
+	}
+	buf := captureLogs(t)
+	for _, tt := range tests {
+		ctx, holder := auth.WithIdentityHolder(context.Background())
+		logRequest(ctx, holder, tt.method, time.Now(), tt.err)
+		record := lastRecord(t, buf)
+		if record["level"] != tt.level {
+			t.Errorf("%s with code %v logged at %v, want %s", tt.method, status.Code(tt.err), record["level"], tt.level)
+		}
+		if record["code"] != status.Code(tt.err).String() {
+			t.Errorf("%s logged code %v, want %v", tt.method, record["code"], status.Code(tt.err))
+		}
+	}
+}
+
+// fakeStream is a server stream receiving the given number of messages, and
+// sending any number of them.
+type fakeStream struct {
+	grpc.ServerStream
+	toReceive int
+}
+
+func (s *fakeStream) Context() context.Context { return context.Background() }
+
+func (s *fakeStream) RecvMsg(interface{}) error {
+	if s.toReceive == 0 {
+		return io.EOF
+	}
+	s.toReceive--
+	return nil
+}
+
+func (s *fakeStream) SendMsg(interface{}) error { return nil }
+
+// echoHandler sends back every message received.
+func echoHandler(_ interface{}, ss grpc.ServerStream) error {
+	for {
+		if err := ss.RecvMsg(nil); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := ss.SendMsg(nil); err != nil {
+			return err
+		}
+	}
+}
+
+func TestLoggingStreamInterceptorCountsMessages(t *testing.T) {
+	buf := captureLogs(t)
+	info := &grpc.StreamServerInfo{FullMethod: "/personguide.PersonGuide/IngestPersons", IsClientStream: true, IsServerStream: true}
+	if err := loggingStreamInterceptor(nil, &fakeStream{toReceive: 3}, info, echoHandler); err != nil {
+		t.Fatal(err)
+	}
+	record := lastRecord(t, buf)
+	// Numbers are decoded as float64.
+	if record["received"] != 3.0 || record["sent"] != 3.0 {
+		t.Errorf("logged %v received and %v sent, want 3 and 3", record["received"], record["sent"])
+	}
+	if record["method"] != info.FullMethod || record["level"] != "INFO" {
+		t.Errorf("logged %v, want the method at INFO level", record)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/jackgris/go-grpc-communication/guide"
+)
+
+// metrics records the calls handled by the server, with the names and labels
+// used by most gRPC servers, so existing dashboards work with them.
+type metrics struct {
+	registry *prometheus.Registry
+
+	started   *prometheus.CounterVec
+	handled   *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	inFlight  *prometheus.GaugeVec
+	received  *prometheus.CounterVec
+	sent      *prometheus.CounterVec
+	perStream *prometheus.HistogramVec
+}
+
+func newMetrics() *metrics {
+	labels := []string{"grpc_type", "grpc_service", "grpc_method"}
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_started_total",
+			Help: "Number of calls started on the server.",
+		}, labels),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Number of calls finished on the server, by status code.",
+		}, append(labels, "grpc_code")),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "How long calls took to finish on the server.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10), // 0.5ms to 2m
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_server_streams_in_flight",
+			Help: "Number of streaming calls in progress.",
+		}, labels),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Number of messages received from clients.",
+		}, labels),
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Number of messages sent to clients.",
+		}, labels),
+		perStream: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_stream_messages",
+			Help:    "Number of messages received or sent by each streaming call.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8), // 1 to 16384
+		}, append(labels, "direction")),
+	}
+	m.registry.MustRegister(
+		m.started, m.handled, m.latency, m.inFlight, m.received, m.sent, m.perStream,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// registerStore adds gauges with the number of persons and address books of
+// the server, which are only reported once they're loaded.
+func (m *metrics) registerStore(s *guide.PersonGuideServer) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "personguide_persons",
+		Help: "Number of persons stored.",
+	}, func() float64 {
+		return float64(s.CountPersons())
+	}))
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "personguide_address_books",
+		Help: "Number of address books stored, under all names.",
+	}, func() float64 {
+		return float64(s.CountAddressBooks())
+	}))
+}
+
+// serve exposes the metrics on /metrics of an HTTP server listening on addr,
+// returning once it's listening.
+func (m *metrics) serve(addr string) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(lis); err != http.ErrServerClosed {
+			slog.Error("Failed while serving metrics", "error", err)
+		}
+	}()
+	slog.Info("Serving metrics", "addr", lis.Addr().String())
+	return srv, nil
+}
+
+// methodLabels returns the values of the type, service and method labels.
+func methodLabels(fullMethod string, clientStream, serverStream bool) []string {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	typ := "unary"
+	switch {
+	case clientStream && serverStream:
+		typ = "bidi_stream"
+	case clientStream:
+		typ = "client_stream"
+	case serverStream:
+		typ = "server_stream"
+	}
+	return []string{typ, service, method}
+}
+
+// unaryInterceptor records the unary calls.
+func (m *metrics) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	labels := methodLabels(info.FullMethod, false, false)
+	m.started.WithLabelValues(labels...).Inc()
+	m.received.WithLabelValues(labels...).Inc()
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.latency.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	m.handled.WithLabelValues(append(labels, status.Code(err).String())...).Inc()
+	if err == nil {
+		m.sent.WithLabelValues(labels...).Inc()
+	}
+	return resp, err
+}
+
+// streamInterceptor records the streaming calls.
+func (m *metrics) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	labels := methodLabels(info.FullMethod, info.IsClientStream, info.IsServerStream)
+	m.started.WithLabelValues(labels...).Inc()
+	inFlight := m.inFlight.WithLabelValues(labels...)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	cs := &countingStream{ServerStream: ss}
+	start := time.Now()
+	err := handler(srv, cs)
+	m.latency.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	m.handled.WithLabelValues(append(labels, status.Code(err).String())...).Inc()
+	m.received.WithLabelValues(labels...).Add(float64(cs.received))
+	m.sent.WithLabelValues(labels...).Add(float64(cs.sent))
+	m.perStream.WithLabelValues(append(labels, "received")...).Observe(float64(cs.received))
+	m.perStream.WithLabelValues(append(labels, "sent")...).Observe(float64(cs.sent))
+	return err
+}
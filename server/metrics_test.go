@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodLabels(t *testing.T) {
+	tests := []struct {
+		clientStream, serverStream bool
+		typ                        string
+	}{
+		{false, false, "unary"},
+		{true, false, "client_stream"},
+		{false, true, "server_stream"},
+		{true, true, "bidi_stream"},
+	}
+	for _, tt := range tests {
+		got := methodLabels("/personguide.PersonGuide/GetPerson", tt.clientStream, tt.serverStream)
+		if want := []string{tt.typ, "personguide.PersonGuide", "GetPerson"}; strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("methodLabels(%v, %v) = %v, want %v", tt.clientStream, tt.serverStream, got, want)
+		}
+	}
+}
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	m := newMetrics()
+	info := &grpc.UnaryServerInfo{FullMethod: "/personguide.PersonGuide/GetPerson"}
+	ok := func(context.Context, interface{}) (interface{}, error) { return "person", nil }
+	notFound := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no person")
+	}
+	for _, handler := range []grpc.UnaryHandler{ok, ok, notFound} {
+		m.unaryInterceptor(context.Background(), nil, info, handler)
+	}
+
+	labels := []string{"unary", "personguide.PersonGuide", "GetPerson"}
+	counters := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"started", testutil.ToFloat64(m.started.WithLabelValues(labels...)), 3},
+		{"handled OK", testutil.ToFloat64(m.handled.WithLabelValues(append(labels, "OK")...)), 2},
+		{"handled NotFound", testutil.ToFloat64(m.handled.WithLabelValues(append(labels, "NotFound")...)), 1},
+		{"received", testutil.ToFloat64(m.received.WithLabelValues(labels...)), 3},
+		{"sent", testutil.ToFloat64(m.sent.WithLabelValues(labels...)), 2},
+	}
+	for _, c := range counters {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if n := testutil.CollectAndCount(m.latency, "grpc_server_handling_seconds"); n != 1 {
+		t.Errorf("got %d latency histograms, want 1", n)
+	}
+}
+
+func TestMetricsStreamInterceptor(t *testing.T) {
+	m := newMetrics()
+	info := &grpc.StreamServerInfo{FullMethod: "/personguide.PersonGuide/IngestPersons", IsClientStream: true, IsServerStream: true}
+	for _, n := range []int{3, 5} {
+		if err := m.streamInterceptor(nil, &fakeStream{toReceive: n}, info, echoHandler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := `
+# HELP grpc_server_handled_total Number of calls finished on the server, by status code.
+# TYPE grpc_server_handled_total counter
+grpc_server_handled_total{grpc_code="OK",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 2
+# HELP grpc_server_msg_received_total Number of messages received from clients.
+# TYPE grpc_server_msg_received_total counter
+grpc_server_msg_received_total{grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 8
+# HELP grpc_server_msg_sent_total Number of messages sent to clients.
+# TYPE grpc_server_msg_sent_total counter
+grpc_server_msg_sent_total{grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 8
+# HELP grpc_server_streams_in_flight Number of streaming calls in progress.
+# TYPE grpc_server_streams_in_flight gauge
+grpc_server_streams_in_flight{grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 0
+# HELP grpc_server_stream_messages Number of messages received or sent by each streaming call.
+# TYPE grpc_server_stream_messages histogram
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="1"} 0
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="4"} 1
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="16"} 2
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="64"} 2
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="256"} 2
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="1024"} 2
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="4096"} 2
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="16384"} 2
+grpc_server_stream_messages_bucket{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="+Inf"} 2
+grpc_server_stream_messages_sum{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 8
+grpc_server_stream_messages_count{direction="received",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="1"} 0
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="4"} 1
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="16"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="64"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="256"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="1024"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="4096"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="16384"} 2
+grpc_server_stream_messages_bucket{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream",le="+Inf"} 2
+grpc_server_stream_messages_sum{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 8
+grpc_server_stream_messages_count{direction="sent",grpc_method="IngestPersons",grpc_service="personguide.PersonGuide",grpc_type="bidi_stream"} 2
+`
+	err := testutil.GatherAndCompare(m.registry, strings.NewReader(want),
+		"grpc_server_handled_total", "grpc_server_msg_received_total", "grpc_server_msg_sent_total",
+		"grpc_server_streams_in_flight", "grpc_server_stream_messages")
+	if err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(m.latency, "grpc_server_handling_seconds"); n != 1 {
+		t.Errorf("got %d latency histograms, want 1", n)
+	}
+}
@@ -1,7 +1,13 @@
 // Package main implements a simple gRPC server that demonstrates how to use gRPC-Go libraries
 // to perform unary, client streaming, server streaming and full duplex RPCs.
 //
-// It implements the person guide service whose definition can be found in personguide/person_guide.proto.
+// It serves the person guide service implemented by the guide package, whose definition can be
+// found in personguide/person_guide.proto.
+//
+// The settings are read from the file given by -config, PERSONGUIDE_SERVER_*
+// variables and flags, and printed by:
+//
+//	server [flags] config dump [--format yaml|toml]
 package main
 
 import (
@@ -9,156 +15,231 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net"
-	"sync"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	"google.golang.org/grpc/credentials"
 
+	"github.com/jackgris/go-grpc-communication/certwatch"
+	"github.com/jackgris/go-grpc-communication/config"
 	"github.com/jackgris/go-grpc-communication/data"
+	"github.com/jackgris/go-grpc-communication/guide"
 	pb "github.com/jackgris/go-grpc-communication/personguide"
+	"github.com/jackgris/go-grpc-communication/tracing"
 )
 
-var (
-	tls        = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
-	certFile   = flag.String("cert_file", "", "The TLS cert file")
-	keyFile    = flag.String("key_file", "", "The TLS key file")
-	jsonDBFile = flag.String("json_db_file", "", "A json file containing a list of features")
-	port       = flag.Int("port", 50051, "The server port")
-)
-
-type PersonGuideServer struct {
-	pb.UnimplementedPersonGuideServer
-	savedPersons []*pb.Person // read-only after initialized
+func main() {
+	var cfg serverConfig
+	cfg.registerFlags(flag.CommandLine)
+	if err := config.Load(flag.CommandLine, os.Args[1:], envPrefix); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if flag.NArg() > 0 {
+		if err := runConfigCommand(flag.CommandLine, flag.Args()); err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	mu          sync.Mutex // protects addressbook
-	addressbook map[string][]*pb.AddressBook
-}
+	logger, err := newLogger(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	// Also used by the log package, for the logs of the libraries.
+	slog.SetDefault(logger)
 
-// GetPhone returns the phone at the given person.
-func (s *PersonGuideServer) GetPhone(ctx context.Context, person *pb.Person) (*pb.PhoneNumber, error) {
-	for _, p := range s.savedPersons {
-		if p.Id == person.Id {
-			return p.GetPhones()[0], nil
-		}
+	// Exits once the deferred calls of run have closed the store and flushed
+	// the spans.
+	if err := run(cfg, logger); err != nil {
+		slog.Error("Server failed", "error", err)
+		os.Exit(1)
 	}
-	// No feature was found, return an unnamed feature
-	return &pb.PhoneNumber{}, errors.New("Not found person")
 }
 
-// ListPersons lists all persons contained within the given adress.
-func (s *PersonGuideServer) ListPersons(adress *pb.Adress, stream pb.PersonGuide_ListPersonsServer) error {
-	fmt.Println("In list persons with adress: ", adress)
-	for _, person := range s.savedPersons {
-		if err := stream.Send(person); err != nil {
-			return err
-		}
+// run serves until a SIGINT or SIGTERM stops the server, or serving fails.
+func run(cfg serverConfig, logger *slog.Logger) error {
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TraceExporter,
+		Endpoint:    cfg.OTLPEndpoint,
+		Insecure:    cfg.OTLPInsecure,
+		File:        cfg.TraceFile,
+		ServiceName: "personguide-server",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
 	}
-	return nil
-}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush spans", "error", err)
+		}
+	}()
 
-// RecordPersons records a list of sequence of persons.
-//
-// It gets a stream of persons, and responds with the "adress book"
-func (s *PersonGuideServer) RecordPersons(stream pb.PersonGuide_RecordPersonsServer) error {
-	var lastPerson *pb.Person
-	for {
-		person, err := stream.Recv()
-		if err != nil && person != nil {
-			ts := timestamppb.New(time.Now())
-			lastPerson = person
-			lastPerson.LastUpdated = ts
-			s.savedPersons = append(s.savedPersons, lastPerson)
+	socketMode, _ := parseFileMode(cfg.UnixSocketMode) // checked by validate
+	listeners, err := listen(listenAddrs(cfg.Listen, cfg.Port), socketMode)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	var opts []grpc.ServerOption
+	if cfg.TLS || cfg.MTLS {
+		if cfg.CertFile == "" {
+			cfg.CertFile = data.Path("x509/server_cert.pem")
 		}
-		if err == io.EOF {
-			// Don't do this in production this is only for example propose
-			p := pb.Person{
-				Name:   "Another part in the world",
-				Id:     11,
-				Email:  "anotherpartintheworld@gmail.com",
-				Phones: phones,
+		if cfg.KeyFile == "" {
+			cfg.KeyFile = data.Path("x509/server_key.pem")
+		}
+		files := []string{cfg.CertFile, cfg.KeyFile}
+		var clientCA, crl string // client certs are only verified with mTLS
+		if cfg.MTLS {
+			if cfg.ClientCAFile == "" {
+				cfg.ClientCAFile = data.Path("x509/client_ca_cert.pem")
 			}
-
-			s.addressbook["book"][0].People = append(s.addressbook["book"][0].People, &p)
-			return stream.SendAndClose(s.addressbook["book"][0])
+			clientCA, crl = cfg.ClientCAFile, cfg.CRL
+			files = append(files, clientCA)
+			if crl != "" {
+				files = append(files, crl)
+			}
+		}
+		load := func() (credentials.TransportCredentials, time.Time, error) {
+			return loadServerCredentials(cfg.CertFile, cfg.KeyFile, clientCA, crl)
 		}
+		creds, err := certwatch.New(load, files, cfg.CertReloadInterval)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to generate credentials: %w", err)
 		}
+		opts = []grpc.ServerOption{grpc.Creds(creds)}
 	}
-}
-
-// RoutePhones receives a stream of message/persons data, and responds with a stream of all
-// phone numbers at each of those persons.
-func (s *PersonGuideServer) RoutePhones(stream pb.PersonGuide_RoutePhonesServer) error {
-	for {
-		person, err := stream.Recv()
-		if err == io.EOF {
-			return nil
+	// Extracts the trace context sent by clients, and traces every call.
+	opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	ready := newReadiness()
+	streams := &streamTracker{}
+	opts = append(opts, grpc.ChainStreamInterceptor(streams.streamInterceptor))
+	var m *metrics
+	if cfg.MetricsAddr != "" {
+		m = newMetrics()
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(m.unaryInterceptor),
+			grpc.ChainStreamInterceptor(m.streamInterceptor),
+		)
+	}
+	authorize, err := newAuthorizer(cfg.JWKSFile, cfg.JWTIssuer, cfg.JWTAudience, cfg.AuthzPolicyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the authorizer: %w", err)
+	}
+	// The PersonGuide calls are checked by the service, after logging them.
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor),
+		grpc.ChainStreamInterceptor(loggingStreamInterceptor, reflectionInterceptor(authorize)),
+	)
+	store, err := openStore(cfg.Store, cfg.StoreFile)
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+	defer func() {
+		// Saves the last writes to disk.
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
 		}
+	}()
+	grpcServer := grpc.NewServer(opts...)
+	s := guide.New(guide.WithStore(store), guide.WithLogger(logger), guide.WithAuth(authorize))
+	s.Register(grpcServer)
+	healthpb.RegisterHealthServer(grpcServer, ready)
+	reflection.Register(grpcServer)
+	if m != nil {
+		m.registerStore(s)
+		metricsServer, err := m.serve(cfg.MetricsAddr)
 		if err != nil {
-			return err
-		}
-		s.mu.Lock()
-		// Note: this copy prevents blocking other clients while serving this one.
-		// We don't need to do a deep copy, because elements in the slice are
-		// insert-only and never modified.
-		rn := make([]*pb.PhoneNumber, len(person.Phones))
-		copy(rn, person.Phones)
-		s.mu.Unlock()
-
-		for _, phone := range rn {
-			if err := stream.Send(phone); err != nil {
-				return err
-			}
+			return fmt.Errorf("failed to serve metrics: %w", err)
 		}
+		defer metricsServer.Close()
 	}
-}
-
-// loadFeatures could loads features from a JSON file or database, now is only for show one way to do this.
-func (s *PersonGuideServer) loadFeatures(filePath string) {
-	fmt.Println("You could load data from the filepath: ", filePath)
-	s.savedPersons = exampleData
-	s.addressbook["book"] = exampleAdressBook
-}
 
-func newServer() *PersonGuideServer {
-	s := &PersonGuideServer{addressbook: make(map[string][]*pb.AddressBook)}
-	s.loadFeatures(*jsonDBFile)
-	return s
-}
+	// Stopping while loading still closes the store and flushes the spans.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
 
-func main() {
-	flag.Parse()
-	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", *port))
-	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+	// Serve health checks while loading, the PersonGuide service is rejected
+	// until the persons are loaded.
+	serveErr := make(chan error, len(listeners))
+	addrs := make([]string, len(listeners))
+	for i, lis := range listeners {
+		addrs[i] = lis.Addr().String()
+		go func(lis net.Listener) {
+			serveErr <- grpcServer.Serve(lis)
+		}(lis)
 	}
-	var opts []grpc.ServerOption
-	if *tls {
-		if *certFile == "" {
-			*certFile = data.Path("x509/server_cert.pem")
+	loaded := make(chan error, 1)
+	go func() {
+		// The example persons are never written to a persistent store, or the
+		// persons deleted from it would come back on the next start.
+		if cfg.JSONDBFile == "" && cfg.Store == "memory" {
+			if err := seedExamples(store); err != nil {
+				loaded <- fmt.Errorf("failed to load example persons: %w", err)
+				return
+			}
 		}
-		if *keyFile == "" {
-			*keyFile = data.Path("x509/server_key.pem")
+		if err := s.Load(cfg.JSONDBFile); err != nil {
+			loaded <- fmt.Errorf("failed to load persons: %w", err)
+			return
 		}
-		creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
-		if err != nil {
-			log.Fatalf("Failed to generate credentials: %v", err)
+		loaded <- nil
+	}()
+
+	for {
+		select {
+		case err := <-loaded:
+			if err != nil {
+				grpcServer.Stop()
+				return err
+			}
+			ready.set(true)
+			slog.Info("Serving", "addrs", addrs, "store", cfg.Store)
+			loaded = nil
+		case err := <-serveErr:
+			grpcServer.Stop()
+			return fmt.Errorf("failed while serving: %w", err)
+		case got := <-sig:
+			slog.Info("Shutting down", "signal", got.String())
+			stopServer(grpcServer, ready, streams, cfg.ShutdownDrain, cfg.ShutdownTimeout, sig)
+			return nil
 		}
-		opts = []grpc.ServerOption{grpc.Creds(creds)}
 	}
-	grpcServer := grpc.NewServer(opts...)
-	pb.RegisterPersonGuideServer(grpcServer, newServer())
-	err = grpcServer.Serve(lis)
-	if err != nil {
-		log.Fatalf("Fail while server running: %v", err)
+}
+
+// seedExamples puts the example data in the store when it's empty, so there
+// is something to look at without a json_db_file. It's only used with the
+// memory store.
+func seedExamples(store guide.Store) error {
+	empty := true
+	err := store.ScanPersons(func(*pb.Person) bool {
+		empty = false
+		return false
+	})
+	if err != nil || !empty {
+		return err
+	}
+	for _, p := range exampleData {
+		if err := store.PutPerson(p); err != nil {
+			return err
+		}
 	}
+	return store.PutAddressBooks("book", exampleAdressBook)
 }
 
 // Example data
@@ -169,10 +250,18 @@ var phones = []*pb.PhoneNumber{
 }
 
 var exampleData = []*pb.Person{
-	{Name: "Juan", Id: 1, Email: "juan@gmail.com", Phones: phones},
-	{Name: "Gabriel", Id: 2, Email: "gabriel@gmail.com", Phones: phones},
-	{Name: "Albert", Id: 3, Email: "albert@gmail.com", Phones: phones},
-	{Name: "Mark", Id: 4, Email: "mark@gmail.com", Phones: phones},
+	{Name: "Juan", Id: 1, Email: "juan@gmail.com", Phones: phones, Addresses: []*pb.Address{
+		{Street: "Av. Corrientes 1234", City: "Buenos Aires", PostalCode: "C1043", Country: "AR", Type: pb.Address_HOME},
+	}},
+	{Name: "Gabriel", Id: 2, Email: "gabriel@gmail.com", Phones: phones, Addresses: []*pb.Address{
+		{Street: "Av. Santa Fe 800", City: "Buenos Aires", PostalCode: "C1059", Country: "AR", Type: pb.Address_WORK},
+	}},
+	{Name: "Albert", Id: 3, Email: "albert@gmail.com", Phones: phones, Addresses: []*pb.Address{
+		{Street: "Bv. San Juan 50", City: "Córdoba", PostalCode: "X5000", Region: "Córdoba", Country: "AR", Type: pb.Address_HOME},
+	}},
+	{Name: "Mark", Id: 4, Email: "mark@gmail.com", Phones: phones, Addresses: []*pb.Address{
+		{Street: "10 Downing Street", City: "London", PostalCode: "SW1A 2AA", Country: "GB", Type: pb.Address_WORK},
+	}},
 	{Name: "Brian", Id: 5, Email: "brian@gmail.com", Phones: phones},
 	{Name: "Kevin", Id: 6, Email: "kevin@gmail.com", Phones: phones},
 	{Name: "Ryan", Id: 7, Email: "ryan@gmail.com", Phones: phones},
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/jackgris/go-grpc-communication/personguide"
+)
+
+// streamTracker counts the streaming calls of the PersonGuide service in
+// progress, to report the ones cut short when the server is stopped
+// forcibly. Health and reflection streams aren't counted.
+type streamTracker struct {
+	active atomic.Int64
+}
+
+// streamInterceptor counts the PersonGuide stream while its handler runs.
+func (t *streamTracker) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !strings.HasPrefix(info.FullMethod, "/"+pb.PersonGuide_ServiceDesc.ServiceName+"/") {
+		return handler(srv, ss)
+	}
+	t.active.Add(1)
+	defer t.active.Add(-1)
+	return handler(srv, ss)
+}
+
+// stopServer reports the server as NOT_SERVING, waits drain for load
+// balancers to notice, and then stops it gracefully: health Watch calls are
+// ended and new calls are refused, while the ones in progress are given until
+// timeout to finish. After that,
+// or if another signal arrives, the server is stopped forcibly, cancelling
+// the calls left.
+func stopServer(server *grpc.Server, ready *readiness, streams *streamTracker, drain, timeout time.Duration, sig <-chan os.Signal) {
+	ready.shutdown()
+	if drain > 0 {
+		slog.Info("Draining before stopping", "delay", drain)
+		select {
+		case <-time.After(drain):
+		case got := <-sig:
+			slog.Warn("Stopping without draining", "signal", got.String())
+		}
+	}
+
+	ready.stopWatches()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		slog.Info("Stopped gracefully")
+		return
+	case <-ctx.Done():
+		slog.Warn("Timed out waiting for calls to finish", "timeout", timeout)
+	case got := <-sig:
+		slog.Warn("Stopping immediately", "signal", got.String())
+	}
+
+	interrupted := streams.active.Load()
+	server.Stop()
+	<-stopped
+	slog.Warn("Stopped forcibly", "interrupted_streams", interrupted)
+}
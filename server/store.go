@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jackgris/go-grpc-communication/guide"
+)
+
+// openStore opens the store of the given kind. The path is only used by
+// stores that keep their data on disk.
+func openStore(kind, path string) (guide.Store, error) {
+	switch kind {
+	case "memory":
+		return guide.NewMemoryStore(), nil
+	case "bolt":
+		return guide.NewBoltStore(path)
+	default:
+		return nil, fmt.Errorf("unknown store %q, must be one of memory or bolt", kind)
+	}
+}
@@ -0,0 +1,98 @@
+// Package tracing sets up OpenTelemetry tracing for the person guide client
+// and server, exporting spans with OTLP to a collector, or to a local file
+// when there is none.
+//
+// The trace context is propagated in the metadata of the calls with the W3C
+// traceparent header, so the spans of the client and the server end up in
+// the same trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Config selects where spans are exported.
+type Config struct {
+	// Exporter is otlp, file, or empty to disable tracing.
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector. If empty, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT variable or localhost:4317 is used.
+	Endpoint string
+	// Insecure disables TLS to the OTLP collector.
+	Insecure bool
+	// File is where spans are written as JSON by the file exporter.
+	File string
+	// ServiceName identifies the process in the traces.
+	ServiceName string
+}
+
+// Setup installs the global tracer provider and propagator for cfg. The
+// returned function flushes the spans not exported yet and must be called
+// before exiting. When tracing is disabled, the no-op provider is kept but
+// the trace context is still propagated.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var closeFile func() error
+	switch cfg.Exporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		opts := []otlptracegrpc.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case "file":
+		var f *os.File
+		f, err = os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		closeFile = f.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, must be otlp or file", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFile != nil {
+			if closeErr := closeFile(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}